@@ -0,0 +1,9 @@
+// Package data berisi file data statis yang di-embed ke binary.
+package data
+
+import _ "embed"
+
+// CommonPasswords adalah daftar password umum bawaan, dipakai jika PASSWORD_COMMON_LIST kosong
+//
+//go:embed common-passwords.txt
+var CommonPasswords []byte
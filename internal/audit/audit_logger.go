@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"google.golang.org/grpc"
+)
+
+type Entry struct {
+	Action     string
+	TargetType string
+	TargetId   string
+	// snapshot sebelum dan sesudah perubahan, dibandingkan per field JSON
+	Before any
+	After  any
+}
+
+type IAuditLogger interface {
+	Record(ctx context.Context, entry Entry) error
+}
+
+type auditLogger struct {
+	auditLogRepository repository.IAuditLogRepository
+}
+
+// Record menyimpan entry ke audit log. Actor, request id, IP dan method
+// diambil dari context request.
+func (l *auditLogger) Record(ctx context.Context, entry Entry) error {
+	changes, err := Diff(entry.Before, entry.After)
+	if err != nil {
+		return err
+	}
+	auditLog := &entity.AuditLog{
+		Id:         uuid.NewString(),
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetId:   entry.TargetId,
+		Changes:    changes,
+		RequestId:  utils.RequestIdFromContext(ctx),
+		IpAddress:  utils.ClientIpFromContext(ctx),
+		CreatedAt:  time.Now(),
+	}
+	if claims, err := jwtentity.GetClaimsFromContext(ctx); err == nil {
+		auditLog.ActorId = claims.Subject
+		if claims.IsImpersonated() {
+			auditLog.ImpersonatorId = claims.Act.Subject
+		}
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		auditLog.Method = method
+	}
+	return l.auditLogRepository.InsertAuditLog(ctx, auditLog)
+}
+
+// Diff membandingkan dua snapshot dan mengembalikan field yang berubah.
+// Snapshot nil dianggap kosong, misalnya ketika data baru dibuat.
+func Diff(before any, after any) ([]entity.AuditChange, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(beforeFields)+len(afterFields))
+	for key := range beforeFields {
+		keys = append(keys, key)
+	}
+	for key := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]entity.AuditChange, 0)
+	for _, key := range keys {
+		if reflect.DeepEqual(beforeFields[key], afterFields[key]) {
+			continue
+		}
+		changes = append(changes, entity.AuditChange{
+			Field:  key,
+			Before: beforeFields[key],
+			After:  afterFields[key],
+		})
+	}
+	return changes, nil
+}
+
+func toFields(snapshot any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if snapshot == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func NewAuditLogger(auditLogRepository repository.IAuditLogRepository) IAuditLogger {
+	return &auditLogger{
+		auditLogRepository: auditLogRepository,
+	}
+}
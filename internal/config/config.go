@@ -0,0 +1,266 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/passwordhash"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/auth"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/ratelimit"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+)
+
+const (
+	EnvironmentDev     = "dev"
+	EnvironmentStaging = "stag"
+	EnvironmentProd    = "prod"
+)
+
+// Config berisi seluruh konfigurasi aplikasi. Nilai diisi berurutan dari
+// default per environment, file YAML (opsional), lalu environment variable / .env.
+type Config struct {
+	Environment string          `yaml:"environment" env:"ENVIRONMENT"`
+	Server      ServerConfig    `yaml:"server"`
+	Database    DatabaseConfig  `yaml:"database"`
+	Jwt         JwtConfig       `yaml:"jwt"`
+	Smtp        SmtpConfig      `yaml:"smtp"`
+	Password    PasswordConfig  `yaml:"password"`
+	Oidc        OidcConfig      `yaml:"oidc"`
+	Worker      WorkerConfig    `yaml:"worker"`
+	Health      HealthConfig    `yaml:"health"`
+	Log         LogConfig       `yaml:"log"`
+	Tracing     TracingConfig   `yaml:"tracing"`
+	RateLimit   RateLimitConfig `yaml:"rate_limit"`
+}
+
+type LogConfig struct {
+	// debug, info, warn, error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// json atau text, default text hanya di dev
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// batas per method per pemanggil, format <requests>/<s|m|h>
+	Default string `yaml:"default" env:"RATE_LIMIT_DEFAULT"`
+	// batas seluruh request per IP, dicek sebelum autentikasi
+	PerIp string `yaml:"per_ip" env:"RATE_LIMIT_PER_IP"`
+	// batas khusus <full method>=<limit>, dipisah koma di env
+	Methods []string `yaml:"methods" env:"RATE_LIMIT_METHODS"`
+}
+
+// MethodLimits mengurai RateLimit.Methods menjadi map full method ke limit
+func (c RateLimitConfig) MethodLimits() (map[string]ratelimit.Limit, error) {
+	limits := make(map[string]ratelimit.Limit, len(c.Methods))
+	for _, entry := range c.Methods {
+		method, raw, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(method, "/") {
+			return nil, fmt.Errorf("invalid entry %q, expected format like /auth.AuthService/Login=10/m", entry)
+		}
+		limit, err := ratelimit.ParseLimit(raw)
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.TrimSpace(method)] = limit
+	}
+	return limits, nil
+}
+
+type TracingConfig struct {
+	// none, stdout atau otlp
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	// host:port collector OTLP gRPC
+	OtlpEndpoint string `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	OtlpInsecure bool   `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+	// 0 sampai 1, porsi trace baru yang direkam
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+type ServerConfig struct {
+	Port int `yaml:"port" env:"SERVER_PORT"`
+	// port REST/JSON gateway, 0 untuk menonaktifkan
+	HttpPort int `yaml:"http_port" env:"SERVER_HTTP_PORT"`
+	// gRPC-Web untuk browser, disajikan di port HTTP yang sama dengan gateway
+	GrpcWeb bool `yaml:"grpc_web" env:"GRPC_WEB_ENABLED"`
+	// origin yang boleh memanggil gRPC-Web dari browser, dipisah koma di env.
+	// Tidak boleh *, request gRPC-Web membawa kredensial (Authorization, API key)
+	CorsAllowedOrigins []string `yaml:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	// jumlah proxy tepercaya (load balancer, ingress) di depan server. Menentukan
+	// entri x-forwarded-for yang dipakai sebagai IP klien, 0 berarti alamat peer
+	TrustedProxyHops int `yaml:"trusted_proxy_hops" env:"TRUSTED_PROXY_HOPS"`
+	// port /metrics Prometheus, dipisah dari port publik. 0 untuk menonaktifkan
+	MetricsPort int `yaml:"metrics_port" env:"METRICS_PORT"`
+	// reflection gRPC, default hanya aktif di dev
+	Reflection bool `yaml:"reflection" env:"GRPC_REFLECTION"`
+	// batas waktu tiap tahap shutdown (request, worker, resource), total bisa
+	// sampai 3x nilai ini, sesuaikan terminationGracePeriodSeconds
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+}
+
+type DatabaseConfig struct {
+	Uri string `yaml:"uri" env:"DB_URI"`
+	// jalankan migration saat startup, matikan jika migration dijalankan
+	// terpisah lewat subcommand migrate
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	// timeout satu kali ping dan total waktu retry koneksi saat startup
+	ConnectTimeout      time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	ConnectRetryTimeout time.Duration `yaml:"connect_retry_timeout" env:"DB_CONNECT_RETRY_TIMEOUT"`
+	// 0 berarti mengikuti setting server
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	// interval log statistik pool, 0 untuk menonaktifkan
+	StatsInterval time.Duration `yaml:"stats_interval" env:"DB_STATS_INTERVAL"`
+}
+
+type JwtConfig struct {
+	SigningAlg       string        `yaml:"signing_alg" env:"JWT_SIGNING_ALG"`
+	KeysDir          string        `yaml:"keys_dir" env:"JWT_KEYS_DIR"`
+	RotationInterval time.Duration `yaml:"rotation_interval" env:"JWT_KEY_ROTATION_INTERVAL"`
+	Overlap          time.Duration `yaml:"overlap" env:"JWT_KEY_OVERLAP"`
+	Propagation      time.Duration `yaml:"propagation" env:"JWT_KEY_PROPAGATION"`
+	SecretKey        string        `yaml:"secret_key" env:"JWT_SECRET_KEY"`
+}
+
+type SmtpConfig struct {
+	// kosong berarti email hanya ditulis ke log
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     string `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+}
+
+type PasswordConfig struct {
+	MinLength         int    `yaml:"min_length" env:"PASSWORD_MIN_LENGTH"`
+	RequireUpper      bool   `yaml:"require_upper" env:"PASSWORD_REQUIRE_UPPER"`
+	RequireLower      bool   `yaml:"require_lower" env:"PASSWORD_REQUIRE_LOWER"`
+	RequireDigit      bool   `yaml:"require_digit" env:"PASSWORD_REQUIRE_DIGIT"`
+	RequireSymbol     bool   `yaml:"require_symbol" env:"PASSWORD_REQUIRE_SYMBOL"`
+	CommonList        string `yaml:"common_list" env:"PASSWORD_COMMON_LIST"`
+	HashAlgorithm     string `yaml:"hash_algorithm" env:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost        int    `yaml:"bcrypt_cost" env:"PASSWORD_BCRYPT_COST"`
+	Argon2MemoryKib   uint32 `yaml:"argon2_memory_kib" env:"PASSWORD_ARGON2_MEMORY_KIB"`
+	Argon2Iterations  uint32 `yaml:"argon2_iterations" env:"PASSWORD_ARGON2_ITERATIONS"`
+	Argon2Parallelism uint8  `yaml:"argon2_parallelism" env:"PASSWORD_ARGON2_PARALLELISM"`
+}
+
+type OidcConfig struct {
+	Providers []OidcProviderConfig `yaml:"providers"`
+}
+
+type OidcProviderConfig struct {
+	Name         string `yaml:"name"`
+	IssuerUrl    string `yaml:"issuer_url"`
+	ClientId     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	RedirectUrl  string `yaml:"redirect_url"`
+}
+
+type WorkerConfig struct {
+	AnonymizeUserInterval         time.Duration `yaml:"anonymize_user_interval" env:"WORKER_ANONYMIZE_USER_INTERVAL"`
+	IdempotencyKeyCleanupInterval time.Duration `yaml:"idempotency_key_cleanup_interval" env:"WORKER_IDEMPOTENCY_KEY_CLEANUP_INTERVAL"`
+}
+
+type HealthConfig struct {
+	Interval time.Duration `yaml:"interval" env:"HEALTH_CHECK_INTERVAL"`
+	Timeout  time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT"`
+	// 0 berarti versi migration terbaru yang dibawa binary
+	RequiredMigrationVersion uint `yaml:"required_migration_version" env:"HEALTH_REQUIRED_MIGRATION_VERSION"`
+}
+
+// Default mengembalikan nilai awal untuk environment tertentu
+func Default(environment string) *Config {
+	cfg := &Config{
+		Environment: environment,
+		Server: ServerConfig{
+			Port:            50051,
+			HttpPort:        8080,
+			GrpcWeb:         true,
+			MetricsPort:     9090,
+			ShutdownTimeout: time.Second * 30,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: logger.FormatJson,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Default: "50/s",
+			PerIp:   "100/s",
+			// method publik yang rawan brute force dan spam email
+			Methods: []string{
+				auth.AuthService_Login_FullMethodName + "=10/m",
+				auth.AuthService_LoginWithOidc_FullMethodName + "=10/m",
+				auth.AuthService_Register_FullMethodName + "=5/m",
+				auth.AuthService_RequestPasswordReset_FullMethodName + "=5/m",
+				auth.AuthService_ResetPassword_FullMethodName + "=5/m",
+				auth.AuthService_ConfirmChangeEmail_FullMethodName + "=10/m",
+				auth.AuthService_ChangePassword_FullMethodName + "=5/m",
+				auth.AuthService_ChangeEmail_FullMethodName + "=5/m",
+			},
+		},
+		Tracing: TracingConfig{
+			Exporter:     tracing.ExporterNone,
+			OtlpEndpoint: "localhost:4317",
+			SampleRatio:  1,
+		},
+		Database: DatabaseConfig{
+			AutoMigrate:         true,
+			MaxOpenConns:        20,
+			MaxIdleConns:        10,
+			ConnMaxLifetime:     time.Minute * 30,
+			ConnMaxIdleTime:     time.Minute * 5,
+			ConnectTimeout:      time.Second * 5,
+			ConnectRetryTimeout: time.Minute,
+			StatementTimeout:    time.Second * 30,
+			StatsInterval:       time.Minute,
+		},
+		Jwt: JwtConfig{
+			SigningAlg:       jwtentity.AlgorithmRS256,
+			RotationInterval: time.Hour * 24 * 30,
+			Overlap:          time.Hour * 24,
+			Propagation:      time.Hour,
+		},
+		Smtp: SmtpConfig{
+			Port: "587",
+		},
+		Password: PasswordConfig{
+			MinLength:         8,
+			RequireUpper:      true,
+			RequireLower:      true,
+			RequireDigit:      true,
+			HashAlgorithm:     passwordhash.AlgorithmArgon2id,
+			BcryptCost:        10,
+			Argon2MemoryKib:   64 * 1024,
+			Argon2Iterations:  3,
+			Argon2Parallelism: 2,
+		},
+		Worker: WorkerConfig{
+			AnonymizeUserInterval:         time.Hour,
+			IdempotencyKeyCleanupInterval: time.Hour,
+		},
+		Health: HealthConfig{
+			Interval: time.Second * 10,
+			Timeout:  time.Second * 2,
+		},
+	}
+
+	switch environment {
+	case EnvironmentDev:
+		cfg.Server.Reflection = true
+		cfg.Jwt.KeysDir = "./keys"
+		cfg.Log.Level = "debug"
+		cfg.Log.Format = logger.FormatText
+	case EnvironmentStaging:
+		cfg.Server.Reflection = true
+	}
+	return cfg
+}
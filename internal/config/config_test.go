@@ -0,0 +1,207 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig(environment string) *Config {
+	cfg := Default(environment)
+	cfg.Database.Uri = "postgres://localhost/furniture"
+	cfg.Jwt.KeysDir = "/var/lib/furniture/keys"
+	cfg.Smtp.Host = "smtp.example.com"
+	cfg.Smtp.From = "no-reply@example.com"
+	return cfg
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	for _, environment := range []string{EnvironmentDev, EnvironmentStaging, EnvironmentProd} {
+		t.Run(environment, func(t *testing.T) {
+			if err := validConfig(environment).Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		mutate      func(cfg *Config)
+		wantErr     string
+	}{
+		{name: "unknown environment", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Environment = "production" }, wantErr: "ENVIRONMENT"},
+		{name: "invalid port", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.Port = 70000 }, wantErr: "SERVER_PORT"},
+		{name: "http port same as grpc port", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.HttpPort = cfg.Server.Port }, wantErr: "SERVER_HTTP_PORT"},
+		{name: "http port disabled", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.HttpPort, cfg.Server.GrpcWeb = 0, false }},
+		{name: "grpc web without http port", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.HttpPort = 0 }, wantErr: "GRPC_WEB_ENABLED"},
+		{name: "cors origin", environment: EnvironmentProd, mutate: func(cfg *Config) { cfg.Server.CorsAllowedOrigins = []string{"https://admin.example.com"} }},
+		{name: "cors wildcard", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.CorsAllowedOrigins = []string{"*"} }, wantErr: "* is not allowed"},
+		{name: "cors origin with path", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.CorsAllowedOrigins = []string{"https://admin.example.com/app"} }, wantErr: "CORS_ALLOWED_ORIGINS"},
+		{name: "negative trusted proxy hops", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.TrustedProxyHops = -1 }, wantErr: "TRUSTED_PROXY_HOPS"},
+		{name: "metrics port same as http port", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.MetricsPort = cfg.Server.HttpPort }, wantErr: "METRICS_PORT"},
+		{name: "zero shutdown timeout", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Server.ShutdownTimeout = 0 }, wantErr: "SERVER_SHUTDOWN_TIMEOUT"},
+		{name: "invalid log level", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Log.Level = "verbose" }, wantErr: "LOG_LEVEL"},
+		{name: "invalid rate limit", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.RateLimit.PerIp = "100" }, wantErr: "RATE_LIMIT_PER_IP"},
+		{name: "invalid rate limit method", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.RateLimit.Methods = []string{"Login=10/m"} }, wantErr: "RATE_LIMIT_METHODS"},
+		// limit tidak dicek jika rate limit dimatikan
+		{name: "rate limit disabled", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.RateLimit.Enabled, cfg.RateLimit.PerIp = false, "100" }},
+		{name: "otlp without endpoint", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Tracing.Exporter, cfg.Tracing.OtlpEndpoint = "otlp", "" }, wantErr: "TRACING_OTLP_ENDPOINT"},
+		{name: "sample ratio above one", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Tracing.SampleRatio = 1.5 }, wantErr: "TRACING_SAMPLE_RATIO"},
+		{name: "missing db uri", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Database.Uri = "" }, wantErr: "DB_URI"},
+		{name: "idle conns above open conns", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Database.MaxIdleConns = cfg.Database.MaxOpenConns + 1 }, wantErr: "DB_MAX_IDLE_CONNS"},
+		{name: "hs256 without secret", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Jwt.SigningAlg = "HS256" }, wantErr: "JWT_SECRET_KEY is required"},
+		{name: "short jwt secret", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Jwt.SecretKey = "short" }, wantErr: "at least 32 characters"},
+		{name: "unsupported signing alg", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Jwt.SigningAlg = "ES256" }, wantErr: "JWT_SIGNING_ALG"},
+		{name: "in-memory keys allowed in dev", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Jwt.KeysDir = "" }},
+		{name: "in-memory keys outside dev", environment: EnvironmentStaging, mutate: func(cfg *Config) { cfg.Jwt.KeysDir = "" }, wantErr: "JWT_KEYS_DIR"},
+		{name: "propagation longer than rotation", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Jwt.Propagation = cfg.Jwt.RotationInterval }, wantErr: "JWT_KEY_PROPAGATION"},
+		{name: "overlap shorter than token", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Jwt.Overlap = time.Hour }, wantErr: "JWT_KEY_OVERLAP"},
+		{name: "log mailer allowed in stag", environment: EnvironmentStaging, mutate: func(cfg *Config) { cfg.Smtp.Host = "" }},
+		{name: "log mailer in prod", environment: EnvironmentProd, mutate: func(cfg *Config) { cfg.Smtp.Host = "" }, wantErr: "SMTP_HOST"},
+		{name: "smtp without from", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Smtp.From = "" }, wantErr: "SMTP_FROM"},
+		{name: "unsupported hash algorithm", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Password.HashAlgorithm = "md5" }, wantErr: "PASSWORD_HASH_ALGORITHM"},
+		{name: "oidc provider without client id", environment: EnvironmentDev, mutate: func(cfg *Config) {
+			cfg.Oidc.Providers = []OidcProviderConfig{{Name: "google", IssuerUrl: "https://accounts.google.com"}}
+		}, wantErr: `oidc provider "google"`},
+		{name: "zero worker interval", environment: EnvironmentDev, mutate: func(cfg *Config) { cfg.Worker.AnonymizeUserInterval = 0 }, wantErr: "WORKER_ANONYMIZE_USER_INTERVAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(tt.environment)
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	cfg := validConfig(EnvironmentProd)
+	cfg.Database.Uri = ""
+	cfg.Smtp.Host = ""
+	cfg.Server.Port = 0
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil")
+	}
+	for _, want := range []string{"DB_URI", "SMTP_HOST", "SERVER_PORT"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want containing %q", err, want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+environment: stag
+server:
+  port: 6000
+database:
+  uri: postgres://yaml/furniture
+jwt:
+  keys_dir: /keys
+oidc:
+  providers:
+    - name: google
+      issuer_url: https://accounts.google.com
+      client_id: from-yaml
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("ENVIRONMENT", "")
+	t.Setenv("DB_URI", "postgres://env/furniture")
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "10s")
+	t.Setenv("RATE_LIMIT_METHODS", "/auth.AuthService/Login=3/m, /auth.AuthService/Register=1/m")
+	t.Setenv("OIDC_PROVIDERS", "google")
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "from-env")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Environment != EnvironmentStaging {
+		t.Errorf("Environment = %q, want %q", cfg.Environment, EnvironmentStaging)
+	}
+	if cfg.Server.Port != 6000 {
+		t.Errorf("Server.Port = %d, want 6000 from yaml", cfg.Server.Port)
+	}
+	if cfg.Database.Uri != "postgres://env/furniture" {
+		t.Errorf("Database.Uri = %q, want env to override yaml", cfg.Database.Uri)
+	}
+	if cfg.Server.ShutdownTimeout != 10*time.Second {
+		t.Errorf("Server.ShutdownTimeout = %s, want 10s", cfg.Server.ShutdownTimeout)
+	}
+	if len(cfg.RateLimit.Methods) != 2 || cfg.RateLimit.Methods[1] != "/auth.AuthService/Register=1/m" {
+		t.Errorf("RateLimit.Methods = %q, want two trimmed entries", cfg.RateLimit.Methods)
+	}
+	if cfg.Server.HttpPort != 8080 || !cfg.Server.GrpcWeb {
+		t.Errorf("Server.HttpPort = %d, GrpcWeb = %v, want default 8080 with grpc-web", cfg.Server.HttpPort, cfg.Server.GrpcWeb)
+	}
+	if len(cfg.Oidc.Providers) != 1 || cfg.Oidc.Providers[0].ClientId != "from-env" || cfg.Oidc.Providers[0].IssuerUrl != "https://accounts.google.com" {
+		t.Errorf("Oidc.Providers = %+v, want yaml provider with client id from env", cfg.Oidc.Providers)
+	}
+}
+
+func TestLoadDefaultsToProd(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("ENVIRONMENT", "")
+	t.Setenv("DB_URI", "postgres://env/furniture")
+	t.Setenv("JWT_KEYS_DIR", "/keys")
+	t.Setenv("SMTP_HOST", "")
+
+	// aturan prod berlaku, bukan default dev yang longgar
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "SMTP_HOST") {
+		t.Fatalf("Load() error = %v, want prod validation", err)
+	}
+
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_FROM", "no-reply@example.com")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Environment != EnvironmentProd || cfg.Server.Reflection {
+		t.Errorf("Environment = %q, Reflection = %v, want prod defaults", cfg.Environment, cfg.Server.Reflection)
+	}
+}
+
+func TestLoadUnknownEnvironment(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("DB_URI", "postgres://env/furniture")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "ENVIRONMENT") {
+		t.Errorf("Load() error = %v, want invalid ENVIRONMENT", err)
+	}
+}
+
+func TestLoadInvalidEnv(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("ENVIRONMENT", EnvironmentDev)
+	t.Setenv("DB_URI", "postgres://env/furniture")
+	t.Setenv("SERVER_PORT", "not-a-number")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "SERVER_PORT") {
+		t.Errorf("Load() error = %v, want invalid SERVER_PORT", err)
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigFile = "config.yaml"
+
+// Load membaca .env, file YAML (CONFIG_FILE, atau config.yaml jika ada) dan
+// environment variable, lalu memvalidasi hasilnya. Environment variable
+// selalu menang atas nilai di file YAML.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("config: failed to read .env: %w", err)
+	}
+
+	path, required := os.Getenv("CONFIG_FILE"), true
+	if path == "" {
+		path, required = defaultConfigFile, false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if required || !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config: failed to read %s: %w", path, err)
+		}
+	}
+
+	// environment harus diketahui lebih dulu untuk memilih default
+	environment := os.Getenv("ENVIRONMENT")
+	if environment == "" && content != nil {
+		var peek struct {
+			Environment string `yaml:"environment"`
+		}
+		if err := yaml.Unmarshal(content, &peek); err != nil {
+			return nil, fmt.Errorf("config: invalid %s: %w", path, err)
+		}
+		environment = peek.Environment
+	}
+	// tanpa ENVIRONMENT dipakai default prod yang paling ketat, bukan dev
+	// yang menyalakan reflection dan key di ./keys
+	if environment == "" {
+		environment = EnvironmentProd
+	}
+
+	cfg := Default(environment)
+	if content != nil {
+		if err := yaml.Unmarshal(content, cfg); err != nil {
+			return nil, fmt.Errorf("config: invalid %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+	applyOidcEnv(cfg)
+	cfg.Environment = environment
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv mengisi field yang punya tag env dari environment variable yang tidak kosong
+func applyEnv(value reflect.Value) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+		key := structField.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+		if err := setField(field, raw); err != nil {
+			return fmt.Errorf("config: invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolean)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(number)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		values := make([]string, 0)
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(number)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// applyOidcEnv membaca OIDC_PROVIDERS (dipisah koma) dan OIDC_<NAMA>_*.
+// Provider yang sudah ada di YAML dengan nama yang sama akan ditimpa.
+func applyOidcEnv(cfg *Config) {
+	names := os.Getenv("OIDC_PROVIDERS")
+	if names == "" {
+		return
+	}
+	providers := make([]OidcProviderConfig, 0)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		provider := OidcProviderConfig{Name: name}
+		for _, existing := range cfg.Oidc.Providers {
+			if existing.Name == name {
+				provider = existing
+			}
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		applyEnvString(&provider.IssuerUrl, prefix+"ISSUER_URL")
+		applyEnvString(&provider.ClientId, prefix+"CLIENT_ID")
+		applyEnvString(&provider.ClientSecret, prefix+"CLIENT_SECRET")
+		applyEnvString(&provider.RedirectUrl, prefix+"REDIRECT_URL")
+		providers = append(providers, provider)
+	}
+	cfg.Oidc.Providers = providers
+}
+
+func applyEnvString(target *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*target = value
+	}
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/passwordhash"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/ratelimit"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+)
+
+// panjang minimum secret HS256 (256 bit)
+const minJwtSecretLength = 32
+
+// Validate mengumpulkan semua kesalahan konfigurasi sekaligus agar bisa
+// diperbaiki dalam satu kali deploy
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("config: "+format, args...))
+	}
+
+	switch c.Environment {
+	case EnvironmentDev, EnvironmentStaging, EnvironmentProd:
+	default:
+		invalid("ENVIRONMENT must be one of %s, %s, %s, got %q", EnvironmentDev, EnvironmentStaging, EnvironmentProd, c.Environment)
+	}
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		invalid("SERVER_PORT must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.HttpPort < 0 || c.Server.HttpPort > 65535 {
+		invalid("SERVER_HTTP_PORT must be between 0 and 65535, got %d", c.Server.HttpPort)
+	} else if c.Server.HttpPort == c.Server.Port {
+		invalid("SERVER_HTTP_PORT must differ from SERVER_PORT")
+	}
+	if c.Server.TrustedProxyHops < 0 {
+		invalid("TRUSTED_PROXY_HOPS must not be negative")
+	}
+	if c.Server.MetricsPort < 0 || c.Server.MetricsPort > 65535 {
+		invalid("METRICS_PORT must be between 0 and 65535, got %d", c.Server.MetricsPort)
+	} else if c.Server.MetricsPort != 0 && (c.Server.MetricsPort == c.Server.Port || c.Server.MetricsPort == c.Server.HttpPort) {
+		invalid("METRICS_PORT must differ from SERVER_PORT and SERVER_HTTP_PORT")
+	}
+	if c.Server.GrpcWeb && c.Server.HttpPort == 0 {
+		invalid("GRPC_WEB_ENABLED requires SERVER_HTTP_PORT")
+	}
+	for _, origin := range c.Server.CorsAllowedOrigins {
+		// gRPC-Web selalu mengizinkan kredensial, wildcard berarti situs mana pun bisa memakai sesi user
+		if origin == "*" {
+			invalid("CORS_ALLOWED_ORIGINS must list origins explicitly, * is not allowed with credentials")
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || strings.TrimSuffix(parsed.Path, "/") != "" {
+			invalid("CORS_ALLOWED_ORIGINS entry %q must be an origin like https://admin.example.com", origin)
+		}
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		invalid("SERVER_SHUTDOWN_TIMEOUT must be positive")
+	}
+	if _, err := logger.New(io.Discard, c.Log.Format, c.Log.Level); err != nil {
+		invalid("LOG_LEVEL must be debug, info, warn or error and LOG_FORMAT must be json or text")
+	}
+	if c.RateLimit.Enabled {
+		if _, err := ratelimit.ParseLimit(c.RateLimit.Default); err != nil {
+			invalid("RATE_LIMIT_DEFAULT: %v", err)
+		}
+		if _, err := ratelimit.ParseLimit(c.RateLimit.PerIp); err != nil {
+			invalid("RATE_LIMIT_PER_IP: %v", err)
+		}
+		if _, err := c.RateLimit.MethodLimits(); err != nil {
+			invalid("RATE_LIMIT_METHODS: %v", err)
+		}
+	}
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOtlp:
+		if c.Tracing.OtlpEndpoint == "" {
+			invalid("TRACING_OTLP_ENDPOINT is required when TRACING_EXPORTER is %s", tracing.ExporterOtlp)
+		}
+	default:
+		invalid("TRACING_EXPORTER must be one of %s, %s, %s, got %q", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOtlp, c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+	if c.Database.Uri == "" {
+		invalid("DB_URI is required")
+	}
+	if c.Database.MaxOpenConns <= 0 {
+		invalid("DB_MAX_OPEN_CONNS must be positive")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		invalid("DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS")
+	}
+	if c.Database.ConnectTimeout <= 0 || c.Database.ConnectRetryTimeout < 0 {
+		invalid("DB_CONNECT_TIMEOUT must be positive and DB_CONNECT_RETRY_TIMEOUT must not be negative")
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 || c.Database.StatementTimeout < 0 || c.Database.StatsInterval < 0 {
+		invalid("DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME, DB_STATEMENT_TIMEOUT and DB_STATS_INTERVAL must not be negative")
+	}
+
+	switch c.Jwt.SigningAlg {
+	case jwtentity.AlgorithmHS256:
+		if c.Jwt.SecretKey == "" {
+			invalid("JWT_SECRET_KEY is required when JWT_SIGNING_ALG is %s", jwtentity.AlgorithmHS256)
+		}
+	case jwtentity.AlgorithmRS256, jwtentity.AlgorithmEdDSA:
+		// tanpa keys dir, key hilang saat restart dan tiap instance punya key berbeda
+		if c.Jwt.KeysDir == "" && c.Environment != EnvironmentDev {
+			invalid("JWT_KEYS_DIR is required outside %s", EnvironmentDev)
+		}
+	default:
+		invalid("JWT_SIGNING_ALG must be one of %s, %s, %s, got %q", jwtentity.AlgorithmRS256, jwtentity.AlgorithmEdDSA, jwtentity.AlgorithmHS256, c.Jwt.SigningAlg)
+	}
+	if c.Jwt.SecretKey != "" && len(c.Jwt.SecretKey) < minJwtSecretLength {
+		invalid("JWT_SECRET_KEY must be at least %d characters", minJwtSecretLength)
+	}
+	if c.Jwt.RotationInterval <= 0 {
+		invalid("JWT_KEY_ROTATION_INTERVAL must be positive")
+	}
+	if c.Jwt.Propagation < 0 || c.Jwt.Propagation >= c.Jwt.RotationInterval {
+		invalid("JWT_KEY_PROPAGATION must be between 0 and JWT_KEY_ROTATION_INTERVAL")
+	}
+	if c.Jwt.Overlap < jwtentity.AccessTokenTTL {
+		invalid("JWT_KEY_OVERLAP must be at least the access token lifetime (%s)", jwtentity.AccessTokenTTL)
+	}
+
+	// tanpa SMTP, token reset password ikut tertulis di log
+	if c.Smtp.Host == "" && c.Environment == EnvironmentProd {
+		invalid("SMTP_HOST is required in %s", EnvironmentProd)
+	}
+	if c.Smtp.Host != "" && c.Smtp.From == "" {
+		invalid("SMTP_FROM is required when SMTP_HOST is set")
+	}
+
+	if c.Password.MinLength < 1 {
+		invalid("PASSWORD_MIN_LENGTH must be positive")
+	}
+	switch c.Password.HashAlgorithm {
+	case passwordhash.AlgorithmArgon2id, passwordhash.AlgorithmBcrypt:
+	default:
+		invalid("PASSWORD_HASH_ALGORITHM must be %s or %s, got %q", passwordhash.AlgorithmArgon2id, passwordhash.AlgorithmBcrypt, c.Password.HashAlgorithm)
+	}
+
+	for _, provider := range c.Oidc.Providers {
+		if provider.IssuerUrl == "" || provider.ClientId == "" {
+			invalid("oidc provider %q requires issuer url and client id", provider.Name)
+		}
+	}
+
+	if c.Worker.AnonymizeUserInterval <= 0 {
+		invalid("WORKER_ANONYMIZE_USER_INTERVAL must be positive")
+	}
+	if c.Worker.IdempotencyKeyCleanupInterval <= 0 {
+		invalid("WORKER_IDEMPOTENCY_KEY_CLEANUP_INTERVAL must be positive")
+	}
+	if c.Health.Interval <= 0 || c.Health.Timeout <= 0 {
+		invalid("HEALTH_CHECK_INTERVAL and HEALTH_CHECK_TIMEOUT must be positive")
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+package entity
+
+import "time"
+
+const MaxAddressesPerUser = 20
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type Address struct {
+	Id            string
+	UserId        string
+	Label         string
+	RecipientName string
+	PhoneNumber   string
+	StreetAddress string
+	Province      string
+	// kota atau kabupaten
+	City string
+	// kecamatan
+	District string
+	// kelurahan atau desa
+	SubDistrict       string
+	PostalCode        string
+	Notes             string
+	Location          *GeoPoint
+	IsDefaultShipping bool
+	IsDefaultBilling  bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	CreatedBy         string
+	UpdatedBy         string
+	DeletedAt         time.Time
+	DeletedBy         string
+	IsDeleted         bool
+}
+
+// AddressSnapshot adalah salinan alamat yang berdiri sendiri, dipakai di export data
+// user. Service order belum ada, saat dibuat simpan snapshot ini (sebagai JSON)
+// pada order agar perubahan alamat tidak mengubah riwayat order.
+type AddressSnapshot struct {
+	AddressId     string    `json:"address_id"`
+	Label         string    `json:"label"`
+	RecipientName string    `json:"recipient_name"`
+	PhoneNumber   string    `json:"phone_number"`
+	StreetAddress string    `json:"street_address"`
+	Province      string    `json:"province"`
+	City          string    `json:"city"`
+	District      string    `json:"district"`
+	SubDistrict   string    `json:"sub_district"`
+	PostalCode    string    `json:"postal_code"`
+	Notes         string    `json:"notes,omitempty"`
+	Location      *GeoPoint `json:"location,omitempty"`
+}
+
+func (a *Address) Snapshot() AddressSnapshot {
+	snapshot := AddressSnapshot{
+		AddressId:     a.Id,
+		Label:         a.Label,
+		RecipientName: a.RecipientName,
+		PhoneNumber:   a.PhoneNumber,
+		StreetAddress: a.StreetAddress,
+		Province:      a.Province,
+		City:          a.City,
+		District:      a.District,
+		SubDistrict:   a.SubDistrict,
+		PostalCode:    a.PostalCode,
+		Notes:         a.Notes,
+	}
+	if a.Location != nil {
+		location := *a.Location
+		snapshot.Location = &location
+	}
+	return snapshot
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// format key: <ApiKeyPrefixTag>_<prefix>_<secret>, yang disimpan hanya prefix dan hash secret
+const ApiKeyPrefixTag = "fk"
+
+type ApiKey struct {
+	Id         string
+	UserId     string
+	Name       string
+	Prefix     string
+	SecretHash string
+	// full method gRPC yang boleh diakses, misalnya "/auth.AuthService/GetProfile" atau "/admin.AdminUserService/*"
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+	CreatedAt  time.Time
+	CreatedBy  string
+}
+
+func (k *ApiKey) IsActive(now time.Time) bool {
+	if !k.RevokedAt.IsZero() {
+		return false
+	}
+	return k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt)
+}
+
+type ApiKeyFilter struct {
+	UserId   string
+	Page     int32
+	PageSize int32
+}
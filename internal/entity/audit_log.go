@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+const (
+	AuditActionUserChangeRole         = "user.change_role"
+	AuditActionUserDisable            = "user.disable"
+	AuditActionUserEnable             = "user.enable"
+	AuditActionUserForcePasswordReset = "user.force_password_reset"
+	AuditActionUserRestore            = "user.restore"
+	AuditActionServiceAccountCreate   = "service_account.create"
+	AuditActionApiKeyCreate           = "api_key.create"
+	AuditActionApiKeyRevoke           = "api_key.revoke"
+	AuditActionUserImpersonate        = "user.impersonate"
+	// request tanpa option (common.audit) yang dijalankan saat impersonation
+	AuditActionImpersonatedRequest = "impersonation.request"
+)
+
+const (
+	AuditTargetUser   = "user"
+	AuditTargetApiKey = "api_key"
+)
+
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+type AuditLog struct {
+	Id         string
+	ActorId    string
+	Action     string
+	TargetType string
+	TargetId   string
+	Changes    []AuditChange
+	RequestId  string
+	IpAddress  string
+	Method     string
+	CreatedAt  time.Time
+	// admin yang melakukan aksi atas nama ActorId
+	ImpersonatorId string
+}
+
+type AuditLogFilter struct {
+	ActorId        string
+	Action         string
+	TargetType     string
+	TargetId       string
+	ImpersonatorId string
+	CreatedFrom    time.Time
+	CreatedTo      time.Time
+	Page           int32
+	PageSize       int32
+}
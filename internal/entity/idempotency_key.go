@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// response disimpan selama ini, retry setelahnya dianggap request baru
+const IdempotencyKeyTTL = time.Hour * 24
+
+// key ditahan selama request pertama berjalan. Jika instance mati sebelum
+// selesai, key bisa dipakai lagi setelah batas ini
+const IdempotencyKeyLockTimeout = time.Minute
+
+type IdempotencyKey struct {
+	// user id pemanggil, key hanya diterima dari request yang sudah diautentikasi
+	Scope       string
+	Method      string
+	Key         string
+	RequestHash string
+	// hasil proto.Marshal response, nil selama request pertama berjalan
+	Response  []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
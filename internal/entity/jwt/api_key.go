@@ -0,0 +1,58 @@
+package jwt
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const ApiKeyMetadataKey = "x-api-key"
+
+// ParseApiKeyFromContext membaca header x-api-key, alternatif dari token Bearer
+func ParseApiKeyFromContext(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(ApiKeyMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+	return values[0], true
+}
+
+// SplitApiKey memecah "<tag>_<prefix>_<secret>" menjadi prefix dan secret
+func SplitApiKey(apiKey string, tag string) (prefix string, secret string, ok bool) {
+	parts := strings.Split(apiKey, "_")
+	if len(parts) != 3 || parts[0] != tag || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// secret API key sudah acak dan panjang, cukup di-hash dengan sha256
+func HashApiKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func CompareApiKeySecret(secretHash string, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(secretHash), []byte(HashApiKeySecret(secret))) == 1
+}
+
+// ScopeAllows mengecek apakah full method gRPC termasuk dalam salah satu scope
+func ScopeAllows(scopes []string, fullMethod string) bool {
+	for _, scope := range scopes {
+		if scope == fullMethod {
+			return true
+		}
+		if strings.HasSuffix(scope, "*") && strings.HasPrefix(fullMethod, strings.TrimSuffix(scope, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestScopeAllows(t *testing.T) {
+	const method = "/admin.AdminUserService/ListUsers"
+	tests := []struct {
+		name   string
+		scopes []string
+		want   bool
+	}{
+		{name: "exact method", scopes: []string{method}, want: true},
+		{name: "service wildcard", scopes: []string{"/admin.AdminUserService/*"}, want: true},
+		{name: "package wildcard", scopes: []string{"/admin.*"}, want: true},
+		{name: "all methods", scopes: []string{"*"}, want: true},
+		{name: "one of many", scopes: []string{"/auth.AuthService/GetProfile", method}, want: true},
+		{name: "other method", scopes: []string{"/admin.AdminUserService/DisableUser"}, want: false},
+		{name: "other service wildcard", scopes: []string{"/admin.AuditLogService/*"}, want: false},
+		// tanpa * scope harus sama persis, bukan prefix
+		{name: "prefix without wildcard", scopes: []string{"/admin.AdminUserService/List"}, want: false},
+		{name: "wildcard not at end", scopes: []string{"/admin.*/ListUsers"}, want: false},
+		{name: "no scopes", scopes: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScopeAllows(tt.scopes, method); got != tt.want {
+				t.Errorf("ScopeAllows(%q) = %v, want %v", tt.scopes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitApiKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiKey     string
+		wantPrefix string
+		wantSecret string
+		wantOk     bool
+	}{
+		{name: "valid", apiKey: "fk_ab12cd34_s3cr3t", wantPrefix: "ab12cd34", wantSecret: "s3cr3t", wantOk: true},
+		{name: "wrong tag", apiKey: "xx_ab12cd34_s3cr3t"},
+		{name: "missing secret", apiKey: "fk_ab12cd34_"},
+		{name: "missing prefix", apiKey: "fk__s3cr3t"},
+		{name: "too many parts", apiKey: "fk_ab12cd34_s3cr3t_extra"},
+		{name: "bearer token", apiKey: "eyJhbGciOiJSUzI1NiJ9.e30.sig"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, secret, ok := SplitApiKey(tt.apiKey, "fk")
+			if prefix != tt.wantPrefix || secret != tt.wantSecret || ok != tt.wantOk {
+				t.Errorf("SplitApiKey() = %q, %q, %v, want %q, %q, %v", prefix, secret, ok, tt.wantPrefix, tt.wantSecret, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCompareApiKeySecret(t *testing.T) {
+	hash := HashApiKeySecret("s3cr3t")
+	if !CompareApiKeySecret(hash, "s3cr3t") {
+		t.Error("CompareApiKeySecret() = false for the correct secret")
+	}
+	if CompareApiKeySecret(hash, "s3cr3T") {
+		t.Error("CompareApiKeySecret() = true for a wrong secret")
+	}
+}
+
+func TestParseApiKeyFromContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   string
+		wantOk bool
+	}{
+		{name: "present", ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(ApiKeyMetadataKey, "fk_a_b")), want: "fk_a_b", wantOk: true},
+		{name: "empty value", ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(ApiKeyMetadataKey, ""))},
+		{name: "other header", ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer x"))},
+		{name: "no metadata", ctx: context.Background()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseApiKeyFromContext(tt.ctx)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ParseApiKeyFromContext() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
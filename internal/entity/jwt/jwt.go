@@ -2,8 +2,6 @@ package jwt
 
 import (
 	"context"
-	"fmt"
-	"os"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
@@ -18,28 +16,18 @@ type JwtClaims struct {
 	Email    string `json:"email"`
 	FullName string `json:"full_name"`
 	Role     string `json:"role"`
-}
 
-func GetClaimsFromToken(token string) (*JwtClaims, error) {
-	//kembalikan token tadi hingga menjadi entity jwt
-	tokenClaims, err := jwt.ParseWithClaims(token, &JwtClaims{}, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-		}
-		return []byte(os.Getenv("JWT_SECRET_KEY")), nil
-	})
+	// Act terisi jika token diterbitkan lewat impersonation (RFC 8693)
+	Act *ActorClaims `json:"act,omitempty"`
+}
 
-	if err != nil {
-		return nil, utils.UnauthenticatedResponse()
-	}
-	if !tokenClaims.Valid {
-		return nil, utils.UnauthenticatedResponse()
-	}
+type ActorClaims struct {
+	Subject  string `json:"sub"`
+	FullName string `json:"full_name"`
+}
 
-	if claims, ok := tokenClaims.Claims.(*JwtClaims); ok && tokenClaims.Valid {
-		return claims, nil
-	}
-	return nil, utils.UnauthenticatedResponse()
+func (jc *JwtClaims) IsImpersonated() bool {
+	return jc.Act != nil
 }
 
 func (jc *JwtClaims) SetToContext(ctx context.Context) context.Context {
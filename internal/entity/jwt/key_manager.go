@@ -0,0 +1,489 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmHS256 = "HS256"
+
+	hmacKid = "hs256"
+
+	// metadata key disimpan sebagai header PEM, bukan dari ModTime file
+	pemHeaderCreatedAt  = "Created-At"
+	pemHeaderActivateAt = "Activate-At"
+
+	// jarak minimal antar reload KeysDir karena kid tidak dikenal, agar token
+	// palsu dengan kid acak tidak membuat server terus membaca disk
+	unknownKidReloadInterval = time.Second * 10
+)
+
+type SigningKey struct {
+	Kid       string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+	// sebelum waktu ini key hanya dipublikasikan di JWKS, belum dipakai untuk sign
+	ActivateAt time.Time
+}
+
+type Jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type KeyManagerConfig struct {
+	// RS256, EdDSA atau HS256 (legacy, memakai HmacSecret)
+	Algorithm string
+	// folder berisi <kid>.pem (PKCS8), kosong = key hanya di memory
+	KeysDir string
+	// umur key sebelum diganti key baru
+	RotationInterval time.Duration
+	// berapa lama key lama masih diterima setelah diganti, minimal sama dengan umur token
+	Overlap time.Duration
+	// berapa lama key baru dipublikasikan di JWKS sebelum dipakai untuk sign, agar
+	// replica lain dan konsumen JWKS sempat memuatnya
+	Propagation time.Duration
+	// secret HS256 lama, tetap diterima untuk verifikasi selama masa migrasi
+	HmacSecret string
+}
+
+type KeyManager struct {
+	mu     sync.RWMutex
+	config KeyManagerConfig
+	keys   []*SigningKey // urut dari ActivateAt paling lama
+
+	reloadMu   sync.Mutex
+	lastReload time.Time
+}
+
+func NewKeyManager(config KeyManagerConfig) (*KeyManager, error) {
+	switch config.Algorithm {
+	case AlgorithmRS256, AlgorithmEdDSA:
+	case AlgorithmHS256:
+		if config.HmacSecret == "" {
+			return nil, errors.New("jwt: HS256 requires a secret key")
+		}
+	default:
+		return nil, fmt.Errorf("jwt: unsupported signing algorithm %q", config.Algorithm)
+	}
+	if config.RotationInterval <= 0 {
+		return nil, errors.New("jwt: rotation interval must be positive")
+	}
+	if config.Propagation < 0 || config.Propagation >= config.RotationInterval {
+		return nil, errors.New("jwt: propagation must be between 0 and the rotation interval")
+	}
+	km := &KeyManager{config: config}
+	if err := km.Load(); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+// Load membaca ulang key dari KeysDir dan membuat key baru bila belum ada
+// atau key terbaru sudah melewati RotationInterval.
+func (km *KeyManager) Load() error {
+	if km.config.Algorithm == AlgorithmHS256 {
+		return nil
+	}
+	if err := km.reload(); err != nil {
+		return err
+	}
+	km.mu.RLock()
+	needRotate := len(km.keys) == 0 || time.Since(km.keys[len(km.keys)-1].CreatedAt) >= km.config.RotationInterval
+	km.mu.RUnlock()
+
+	if needRotate {
+		return km.Rotate()
+	}
+	return nil
+}
+
+func (km *KeyManager) reload() error {
+	km.reloadMu.Lock()
+	defer km.reloadMu.Unlock()
+	return km.reloadLocked()
+}
+
+func (km *KeyManager) reloadLocked() error {
+	if km.config.KeysDir == "" {
+		return nil
+	}
+	keys, err := km.readKeysDir()
+	if err != nil {
+		return err
+	}
+	km.lastReload = time.Now()
+	km.mu.Lock()
+	km.keys = keys
+	km.mu.Unlock()
+	return nil
+}
+
+// reloadUnknownKid membaca ulang KeysDir saat token memakai kid yang belum
+// dikenal, misalnya key baru dari replica lain. Dibatasi unknownKidReloadInterval.
+func (km *KeyManager) reloadUnknownKid() bool {
+	km.reloadMu.Lock()
+	defer km.reloadMu.Unlock()
+	if km.config.KeysDir == "" || time.Since(km.lastReload) < unknownKidReloadInterval {
+		return false
+	}
+	if err := km.reloadLocked(); err != nil {
+		slog.Warn("jwt: failed to reload signing keys", "error", err)
+		return false
+	}
+	return true
+}
+
+// Rotate membuat signing key baru. Key baru langsung dipublikasikan di JWKS
+// tetapi baru dipakai untuk sign setelah masa Propagation, kecuali belum ada
+// key aktif sama sekali. Key lama tetap dipakai untuk verifikasi sampai masa
+// Overlap setelah key baru aktif habis.
+func (km *KeyManager) Rotate() error {
+	if km.config.Algorithm == AlgorithmHS256 {
+		return nil
+	}
+	key, err := generateSigningKey(km.config.Algorithm)
+	if err != nil {
+		return err
+	}
+	km.mu.RLock()
+	if km.activeKeyLocked() != nil {
+		key.ActivateAt = key.CreatedAt.Add(km.config.Propagation)
+	}
+	km.mu.RUnlock()
+	if km.config.KeysDir != "" {
+		if err := writeKeyFile(km.config.KeysDir, key); err != nil {
+			return err
+		}
+	}
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	km.keys = append(km.keys, key)
+	km.keys = km.pruneLocked()
+	slog.Info("jwt: rotated signing key", "kid", key.Kid, "alg", key.Algorithm, "activate_at", key.ActivateAt)
+	return nil
+}
+
+// RunRotation menjalankan pengecekan rotasi secara berkala sampai ctx selesai,
+// dipanggil di goroutine sendiri.
+func (km *KeyManager) RunRotation(ctx context.Context) {
+	if km.config.Algorithm == AlgorithmHS256 {
+		return
+	}
+	// replica lain harus sudah memuat key baru sebelum key itu aktif
+	interval := time.Hour
+	if km.config.RotationInterval < interval {
+		interval = km.config.RotationInterval
+	}
+	if km.config.Propagation > 0 && km.config.Propagation/2 < interval {
+		interval = km.config.Propagation / 2
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := km.Load(); err != nil {
+				slog.Error("jwt: failed to rotate signing key", "error", err)
+			}
+		}
+	}
+}
+
+func (km *KeyManager) SignClaims(claims JwtClaims) (string, error) {
+	if km.config.Algorithm == AlgorithmHS256 {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = hmacKid
+		return token.SignedString([]byte(km.config.HmacSecret))
+	}
+
+	km.mu.RLock()
+	key := km.activeKeyLocked()
+	km.mu.RUnlock()
+	if key == nil {
+		return "", errors.New("jwt: no signing key available")
+	}
+
+	token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.Private)
+}
+
+func (km *KeyManager) GetClaimsFromToken(token string) (*JwtClaims, error) {
+	//kembalikan token tadi hingga menjadi entity jwt
+	tokenClaims, err := jwt.ParseWithClaims(token, &JwtClaims{}, km.keyFunc)
+	if err != nil {
+		return nil, utils.UnauthenticatedResponse()
+	}
+	if claims, ok := tokenClaims.Claims.(*JwtClaims); ok && tokenClaims.Valid {
+		return claims, nil
+	}
+	return nil, utils.UnauthenticatedResponse()
+}
+
+func (km *KeyManager) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+		// token lama tidak punya kid
+		if km.config.HmacSecret == "" || (kid != "" && kid != hmacKid) {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return []byte(km.config.HmacSecret), nil
+	}
+
+	key := km.findKey(kid)
+	if key == nil && km.reloadUnknownKid() {
+		key = km.findKey(kid)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown kid: %q", kid)
+	}
+	if t.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+	return key.Private.Public(), nil
+}
+
+func (km *KeyManager) findKey(kid string) *SigningKey {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	for _, key := range km.keys {
+		if key.Kid == kid && km.isVerifiable(key) {
+			return key
+		}
+	}
+	return nil
+}
+
+// Jwks mengembalikan public key yang masih berlaku untuk verifikasi.
+func (km *KeyManager) Jwks() []Jwk {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	jwks := make([]Jwk, 0, len(km.keys))
+	for i := len(km.keys) - 1; i >= 0; i-- {
+		key := km.keys[i]
+		if !km.isVerifiable(key) {
+			continue
+		}
+		jwk := Jwk{
+			Kid: key.Kid,
+			Use: "sig",
+			Alg: key.Algorithm,
+		}
+		switch pub := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks
+}
+
+// activeKeyLocked mengembalikan key terbaru yang sudah aktif
+func (km *KeyManager) activeKeyLocked() *SigningKey {
+	now := time.Now()
+	for i := len(km.keys) - 1; i >= 0; i-- {
+		if !km.keys[i].ActivateAt.After(now) {
+			return km.keys[i]
+		}
+	}
+	return nil
+}
+
+// key yang belum aktif dan key aktif selalu berlaku, key lama berlaku sampai
+// Overlap setelah key penggantinya aktif
+func (km *KeyManager) isVerifiable(key *SigningKey) bool {
+	for i, k := range km.keys {
+		if k != key {
+			continue
+		}
+		if i == len(km.keys)-1 {
+			return true
+		}
+		return time.Since(km.keys[i+1].ActivateAt) < km.config.Overlap
+	}
+	return false
+}
+
+func (km *KeyManager) pruneLocked() []*SigningKey {
+	kept := make([]*SigningKey, 0, len(km.keys))
+	for _, key := range km.keys {
+		if km.isVerifiable(key) {
+			kept = append(kept, key)
+			continue
+		}
+		if km.config.KeysDir != "" {
+			if err := os.Remove(filepath.Join(km.config.KeysDir, key.Kid+".pem")); err != nil && !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("jwt: failed to remove retired key", "kid", key.Kid, "error", err)
+			}
+		}
+	}
+	return kept
+}
+
+func (km *KeyManager) readKeysDir() ([]*SigningKey, error) {
+	if km.config.KeysDir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(km.config.KeysDir, 0o700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(km.config.KeysDir)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*SigningKey, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+		key, err := readKeyFile(filepath.Join(km.config.KeysDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		key.Kid = strings.TrimSuffix(entry.Name(), ".pem")
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].ActivateAt.Before(keys[j].ActivateAt)
+	})
+	return keys, nil
+}
+
+func readKeyFile(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("jwt: %s is not a PEM file", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: parse %s: %w", path, err)
+	}
+	key := &SigningKey{}
+	switch privateKey := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.Private = AlgorithmRS256, privateKey
+	case ed25519.PrivateKey:
+		key.Algorithm, key.Private = AlgorithmEdDSA, privateKey
+	default:
+		return nil, fmt.Errorf("jwt: unsupported key type in %s", path)
+	}
+	// file lama tanpa header dianggap key paling lama yang sudah aktif
+	if key.CreatedAt, err = parsePemTime(block.Headers, pemHeaderCreatedAt); err != nil {
+		return nil, fmt.Errorf("jwt: parse %s: %w", path, err)
+	}
+	if key.ActivateAt, err = parsePemTime(block.Headers, pemHeaderActivateAt); err != nil {
+		return nil, fmt.Errorf("jwt: parse %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func parsePemTime(headers map[string]string, name string) (time.Time, error) {
+	value, ok := headers[name]
+	if !ok {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func writeKeyFile(dir string, key *SigningKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return err
+	}
+	data := pem.EncodeToMemory(&pem.Block{
+		Type: "PRIVATE KEY",
+		Headers: map[string]string{
+			pemHeaderCreatedAt:  key.CreatedAt.UTC().Format(time.RFC3339Nano),
+			pemHeaderActivateAt: key.ActivateAt.UTC().Format(time.RFC3339Nano),
+		},
+		Bytes: der,
+	})
+	path := filepath.Join(dir, key.Kid+".pem")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("jwt: unsupported signing algorithm %q", algorithm)
+	}
+	now := time.Now()
+	return &SigningKey{
+		Kid:        uuid.NewString(),
+		Algorithm:  algorithm,
+		Private:    private,
+		CreatedAt:  now,
+		ActivateAt: now,
+	}, nil
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
@@ -0,0 +1,254 @@
+package jwt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestClaims() JwtClaims {
+	now := time.Now()
+	return JwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Role: "customer",
+	}
+}
+
+func tokenKid(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JwtClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestNewKeyManagerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  KeyManagerConfig
+		wantErr bool
+	}{
+		{name: "eddsa", config: KeyManagerConfig{Algorithm: AlgorithmEdDSA, RotationInterval: time.Hour}},
+		{name: "hs256", config: KeyManagerConfig{Algorithm: AlgorithmHS256, RotationInterval: time.Hour, HmacSecret: "secret"}},
+		{name: "hs256 without secret", config: KeyManagerConfig{Algorithm: AlgorithmHS256, RotationInterval: time.Hour}, wantErr: true},
+		{name: "unsupported algorithm", config: KeyManagerConfig{Algorithm: "ES256", RotationInterval: time.Hour}, wantErr: true},
+		{name: "zero rotation interval", config: KeyManagerConfig{Algorithm: AlgorithmEdDSA}, wantErr: true},
+		{name: "propagation longer than rotation", config: KeyManagerConfig{Algorithm: AlgorithmEdDSA, RotationInterval: time.Hour, Propagation: time.Hour}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyManager(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKeyManager() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyManagerRotate(t *testing.T) {
+	tests := []struct {
+		name         string
+		overlap      time.Duration
+		wantOldValid bool
+		wantJwks     int
+	}{
+		{name: "old key kept during overlap", overlap: time.Hour, wantOldValid: true, wantJwks: 2},
+		{name: "old key retired without overlap", overlap: 0, wantOldValid: false, wantJwks: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km, err := NewKeyManager(KeyManagerConfig{Algorithm: AlgorithmEdDSA, RotationInterval: time.Hour, Overlap: tt.overlap})
+			if err != nil {
+				t.Fatalf("NewKeyManager() error = %v", err)
+			}
+			oldToken, err := km.SignClaims(newTestClaims())
+			if err != nil {
+				t.Fatalf("SignClaims() error = %v", err)
+			}
+
+			if err := km.Rotate(); err != nil {
+				t.Fatalf("Rotate() error = %v", err)
+			}
+			newToken, err := km.SignClaims(newTestClaims())
+			if err != nil {
+				t.Fatalf("SignClaims() error = %v", err)
+			}
+
+			if tokenKid(t, newToken) == tokenKid(t, oldToken) {
+				t.Error("token after rotation is signed with the old kid")
+			}
+			if _, err := km.GetClaimsFromToken(newToken); err != nil {
+				t.Errorf("GetClaimsFromToken(new) error = %v", err)
+			}
+			if _, err := km.GetClaimsFromToken(oldToken); (err == nil) != tt.wantOldValid {
+				t.Errorf("GetClaimsFromToken(old) error = %v, wantOldValid %v", err, tt.wantOldValid)
+			}
+			if got := len(km.Jwks()); got != tt.wantJwks {
+				t.Errorf("len(Jwks()) = %d, want %d", got, tt.wantJwks)
+			}
+		})
+	}
+}
+
+func TestKeyManagerKeysDir(t *testing.T) {
+	dir := t.TempDir()
+	config := KeyManagerConfig{Algorithm: AlgorithmEdDSA, KeysDir: dir, RotationInterval: time.Hour, Overlap: time.Hour}
+
+	first, err := NewKeyManager(config)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	token, err := first.SignClaims(newTestClaims())
+	if err != nil {
+		t.Fatalf("SignClaims() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, tokenKid(t, token)+".pem")); err != nil {
+		t.Fatalf("signing key not written to keys dir: %v", err)
+	}
+
+	// replica lain atau server setelah restart memakai key yang sama
+	second, err := NewKeyManager(config)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	if _, err := second.GetClaimsFromToken(token); err != nil {
+		t.Errorf("GetClaimsFromToken() error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("keys dir has %d files, want 1 (no rotation on reload)", len(entries))
+	}
+}
+
+func TestKeyManagerHmacLegacy(t *testing.T) {
+	km, err := NewKeyManager(KeyManagerConfig{Algorithm: AlgorithmEdDSA, RotationInterval: time.Hour, HmacSecret: "legacy-secret"})
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims()).SignedString([]byte("legacy-secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	if _, err := km.GetClaimsFromToken(legacy); err != nil {
+		t.Errorf("GetClaimsFromToken(legacy hs256) error = %v", err)
+	}
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims()).SignedString([]byte("other-secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	if _, err := km.GetClaimsFromToken(forged); err == nil {
+		t.Error("GetClaimsFromToken(forged hs256) accepted a token with the wrong secret")
+	}
+}
+
+func TestKeyManagerPropagation(t *testing.T) {
+	km, err := NewKeyManager(KeyManagerConfig{Algorithm: AlgorithmEdDSA, RotationInterval: time.Hour * 24, Overlap: time.Hour, Propagation: time.Hour})
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	// key pertama langsung aktif karena belum ada key lain
+	oldToken, err := km.SignClaims(newTestClaims())
+	if err != nil {
+		t.Fatalf("SignClaims() error = %v", err)
+	}
+	if err := km.Rotate(); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	next := km.keys[len(km.keys)-1]
+
+	published := false
+	for _, jwk := range km.Jwks() {
+		published = published || jwk.Kid == next.Kid
+	}
+	if !published {
+		t.Error("next key is not published in JWKS before activation")
+	}
+	token, err := km.SignClaims(newTestClaims())
+	if err != nil {
+		t.Fatalf("SignClaims() error = %v", err)
+	}
+	if got := tokenKid(t, token); got != tokenKid(t, oldToken) {
+		t.Errorf("token signed with kid %q before propagation ended, want current kid", got)
+	}
+
+	// masa propagasi selesai
+	next.ActivateAt = time.Now().Add(-time.Second)
+	token, err = km.SignClaims(newTestClaims())
+	if err != nil {
+		t.Fatalf("SignClaims() error = %v", err)
+	}
+	if got := tokenKid(t, token); got != next.Kid {
+		t.Errorf("token signed with kid %q after activation, want %q", got, next.Kid)
+	}
+	if _, err := km.GetClaimsFromToken(oldToken); err != nil {
+		t.Errorf("GetClaimsFromToken(old) error = %v, want accepted during overlap", err)
+	}
+}
+
+func TestKeyManagerReloadUnknownKid(t *testing.T) {
+	dir := t.TempDir()
+	config := KeyManagerConfig{Algorithm: AlgorithmEdDSA, KeysDir: dir, RotationInterval: time.Hour, Overlap: time.Hour}
+	issuer, err := NewKeyManager(config)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	verifier, err := NewKeyManager(config)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+
+	// replica lain merotasi key setelah verifier terakhir membaca KeysDir
+	if err := issuer.Rotate(); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	token, err := issuer.SignClaims(newTestClaims())
+	if err != nil {
+		t.Fatalf("SignClaims() error = %v", err)
+	}
+
+	if _, err := verifier.GetClaimsFromToken(token); err == nil {
+		t.Error("GetClaimsFromToken() reloaded keys again within the rate limit")
+	}
+	verifier.lastReload = time.Now().Add(-unknownKidReloadInterval)
+	if _, err := verifier.GetClaimsFromToken(token); err != nil {
+		t.Errorf("GetClaimsFromToken() error = %v, want unknown kid to trigger a reload", err)
+	}
+}
+
+func TestKeyManagerKeyMetadata(t *testing.T) {
+	dir := t.TempDir()
+	config := KeyManagerConfig{Algorithm: AlgorithmEdDSA, KeysDir: dir, RotationInterval: time.Hour, Overlap: time.Hour}
+	km, err := NewKeyManager(config)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	key := km.keys[0]
+
+	// menyalin atau restore backup mengubah ModTime, metadata key tidak boleh ikut berubah
+	old := time.Now().Add(-time.Hour * 24 * 365)
+	if err := os.Chtimes(filepath.Join(dir, key.Kid+".pem"), old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	reloaded, err := NewKeyManager(config)
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	if len(reloaded.keys) != 1 {
+		t.Fatalf("got %d keys, want 1 (no rotation because of ModTime)", len(reloaded.keys))
+	}
+	if got := reloaded.keys[0]; !got.CreatedAt.Equal(key.CreatedAt) || !got.ActivateAt.Equal(key.ActivateAt) {
+		t.Errorf("reloaded key times = %s, %s, want %s, %s", got.CreatedAt, got.ActivateAt, key.CreatedAt, key.ActivateAt)
+	}
+}
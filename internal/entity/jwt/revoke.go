@@ -0,0 +1,36 @@
+package jwt
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gocache "github.com/patrickmn/go-cache"
+)
+
+const AccessTokenTTL = time.Hour * 24
+
+// token impersonation sengaja dibuat pendek, admin harus meminta ulang jika habis
+const ImpersonationTokenTTL = time.Minute * 15
+
+// IsIssuedBefore mengecek apakah token terbit sebelum tokens_valid_after user,
+// yaitu saat role berubah, akun dinonaktifkan atau password direset.
+func IsIssuedBefore(issuedAt *jwt.NumericDate, validAfter time.Time) bool {
+	if validAfter.IsZero() {
+		return false
+	}
+	if issuedAt == nil {
+		return true
+	}
+	return issuedAt.Time.Before(validAfter.Truncate(time.Second))
+}
+
+// UserStateCacheKey adalah key cache status user yang dibaca auth middleware
+func UserStateCacheKey(userId string) string {
+	return "user_state:" + userId
+}
+
+// ForgetUserState menghapus cache status user di instance ini agar perubahan
+// langsung berlaku, replica lain mengikuti setelah cache-nya kedaluwarsa.
+func ForgetUserState(cacheService *gocache.Cache, userId string) {
+	cacheService.Delete(UserStateCacheKey(userId))
+}
@@ -0,0 +1,33 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestIsIssuedBefore(t *testing.T) {
+	validAfter := time.Date(2024, 1, 1, 10, 0, 0, 500_000_000, time.UTC)
+	tests := []struct {
+		name       string
+		issuedAt   *jwt.NumericDate
+		validAfter time.Time
+		want       bool
+	}{
+		{name: "never revoked", issuedAt: jwt.NewNumericDate(validAfter.Add(-time.Hour)), want: false},
+		{name: "issued before", issuedAt: jwt.NewNumericDate(validAfter.Add(-time.Second)), validAfter: validAfter, want: true},
+		{name: "issued after", issuedAt: jwt.NewNumericDate(validAfter.Add(time.Second)), validAfter: validAfter, want: false},
+		// iat hanya presisi detik, token yang terbit di detik yang sama tetap diterima
+		{name: "issued in same second", issuedAt: jwt.NewNumericDate(validAfter.Truncate(time.Second)), validAfter: validAfter, want: false},
+		{name: "missing iat", issuedAt: nil, validAfter: validAfter, want: true},
+		{name: "missing iat never revoked", issuedAt: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsIssuedBefore(tt.issuedAt, tt.validAfter); got != tt.want {
+				t.Errorf("IsIssuedBefore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+const PasswordResetTokenTTL = time.Hour * 24
+
+// PasswordResetToken adalah token reset password yang dikirim ke email user.
+// Hanya hash token yang disimpan.
+type PasswordResetToken struct {
+	TokenHash string
+	UserId    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+const PendingEmailChangeTTL = time.Hour
+
+// PendingEmailChange adalah email baru yang menunggu dikonfirmasi lewat token
+// yang dikirim ke alamat tersebut. Hanya hash token yang disimpan.
+type PendingEmailChange struct {
+	TokenHash string
+	UserId    string
+	NewEmail  string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
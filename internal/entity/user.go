@@ -2,10 +2,14 @@ package entity
 
 import "time"
 
+// data pribadi akun yang dihapus dianonimkan setelah masa tenggang ini
+const AccountDeletionGracePeriod = time.Hour * 24 * 30
 
 const (
-	UserRoleAdmin = "admin"
+	UserRoleAdmin    = "admin"
 	UserRoleCustomer = "customer"
+	// akun non-interaktif untuk integrasi (ERP, marketplace), login hanya lewat API key
+	UserRoleServiceAccount = "service_account"
 )
 
 type UserRole struct {
@@ -22,16 +26,39 @@ type UserRole struct {
 }
 
 type User struct {
-	Id        string
-	FullName  string
-	Email     string
-	Password  string
-	RoleCode  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	CreatedBy string
-	UpdatedBy string
-	DeletedAt time.Time
-	DeletedBy string
-	IsDeleted bool
+	Id                    string
+	FullName              string
+	Email                 string
+	Password              string
+	RoleCode              string
+	PhoneNumber           string
+	AvatarUrl             string
+	Preferences           map[string]string
+	IsDisabled            bool
+	PasswordResetRequired bool
+	TokensValidAfter      time.Time
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	CreatedBy             string
+	UpdatedBy             string
+	DeletedAt             time.Time
+	DeletedBy             string
+	IsDeleted             bool
+}
+
+const (
+	UserDeletedStatusActive  = "active"
+	UserDeletedStatusDeleted = "deleted"
+	UserDeletedStatusAll     = "all"
+)
+
+type UserFilter struct {
+	RoleCode      string
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	DeletedStatus string
+	// cari berdasarkan nama atau email
+	Search   string
+	Page     int32
+	PageSize int32
 }
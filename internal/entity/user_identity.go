@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// UserIdentity menghubungkan user dengan akun di provider OIDC (misalnya Google)
+type UserIdentity struct {
+	Id        string
+	UserId    string
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
@@ -0,0 +1,127 @@
+package gateway
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/grpcmiddleware"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/address"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/admin"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/auth"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/common"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:embed openapi/api.swagger.json
+var openApiDocument []byte
+
+// header HTTP yang diteruskan sebagai metadata gRPC selain Authorization,
+// yang selalu diteruskan oleh gateway. x-request-id diteruskan lewat
+// RequestIdClientInterceptor dari ctx yang diisi RequestIdHandler
+var forwardedHeaders = map[string]bool{
+	jwtentity.ApiKeyMetadataKey:              true,
+	grpcmiddleware.IdempotencyKeyMetadataKey: true,
+}
+
+type baseResponseMessage interface {
+	GetBase() *common.BaseResponse
+}
+
+// NewHandler membuat handler REST/JSON yang meneruskan request ke server gRPC
+// di grpcEndpoint, sehingga semua interceptor (auth, audit, validasi) tetap berlaku.
+// gatewaySecret dikirim di setiap panggilan agar server menghitung gateway sebagai proxy.
+func NewHandler(ctx context.Context, grpcEndpoint string, gatewaySecret string) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingMatcher(runtime.MetadataHeaderPrefix)),
+		runtime.WithOutgoingTrailerMatcher(outgoingMatcher(runtime.MetadataTrailerPrefix)),
+		runtime.WithForwardResponseOption(forwardStatusCode),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+	)
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(grpcmiddleware.RequestIdClientInterceptor, grpcmiddleware.GatewayClientInterceptor(gatewaySecret)),
+		// meneruskan trace context dari TraceContextHandler ke server lewat metadata traceparent
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+	registers := []func(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error{
+		auth.RegisterAuthServiceHandlerFromEndpoint,
+		admin.RegisterAdminUserServiceHandlerFromEndpoint,
+		admin.RegisterAuditLogServiceHandlerFromEndpoint,
+		admin.RegisterApiKeyServiceHandlerFromEndpoint,
+		address.RegisterAddressServiceHandlerFromEndpoint,
+	}
+	for _, register := range registers {
+		if err := register(ctx, mux, grpcEndpoint, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("GET /openapi.json", serveOpenApi)
+	handler.Handle("/", mux)
+	return handler, nil
+}
+
+func incomingHeaderMatcher(key string) (string, bool) {
+	key = strings.ToLower(key)
+	if forwardedHeaders[key] {
+		return key, true
+	}
+	// hanya diisi GatewayClientInterceptor, bukan oleh klien
+	if key == strings.ToLower(runtime.MetadataHeaderPrefix)+grpcmiddleware.GatewayMetadataKey {
+		return "", false
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// sama dengan matcher bawaan, kecuali x-request-id yang sudah ditulis
+// RequestIdHandler dan tidak perlu diulang sebagai Grpc-Metadata-*, serta
+// retry-after yang diteruskan sebagai header standar untuk response 429
+func outgoingMatcher(prefix string) runtime.HeaderMatcherFunc {
+	return func(key string) (string, bool) {
+		if key == utils.RequestIdMetadataKey {
+			return "", false
+		}
+		if key == grpcmiddleware.RetryAfterMetadataKey {
+			return "Retry-After", true
+		}
+		return prefix + key, true
+	}
+}
+
+// response sukses dari service membawa status di base (mis. 400 untuk validasi),
+// status HTTP disamakan agar klien REST tidak perlu membaca body
+func forwardStatusCode(ctx context.Context, w http.ResponseWriter, message proto.Message) error {
+	res, ok := message.(baseResponseMessage)
+	if !ok || res.GetBase() == nil {
+		return nil
+	}
+	statusCode := int(res.GetBase().GetStatusCode())
+	if statusCode >= 200 && statusCode < 600 && statusCode != http.StatusOK {
+		w.WriteHeader(statusCode)
+	}
+	return nil
+}
+
+func serveOpenApi(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openApiDocument)
+}
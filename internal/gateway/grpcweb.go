@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"google.golang.org/grpc"
+)
+
+// header yang boleh dikirim browser pada request gRPC-Web
+var grpcWebAllowedHeaders = []string{
+	"authorization",
+	"content-type",
+	"x-grpc-web",
+	"x-user-agent",
+	"grpc-timeout",
+	jwtentity.ApiKeyMetadataKey,
+	"x-request-id",
+	"idempotency-key",
+}
+
+// WithGrpcWeb melayani request gRPC-Web langsung ke server gRPC (interceptor
+// yang sama dengan klien gRPC biasa), request lain diteruskan ke next.
+// CORS hanya diizinkan untuk origin di allowedOrigins.
+func WithGrpcWeb(server *grpc.Server, allowedOrigins []string, next http.Handler) http.Handler {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origins[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	wrapped := grpcweb.WrapServer(server,
+		grpcweb.WithOriginFunc(func(origin string) bool {
+			return origins[origin]
+		}),
+		grpcweb.WithAllowedRequestHeaders(grpcWebAllowedHeaders),
+		grpcweb.WithWebsockets(false),
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wrapped.IsGrpcWebRequest(r) || wrapped.IsAcceptableGrpcCorsRequest(r) {
+			wrapped.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
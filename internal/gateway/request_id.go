@@ -0,0 +1,22 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+)
+
+// RequestIdHandler memakai X-Request-Id dari klien atau membuat yang baru,
+// lalu menuliskannya ke header request (diteruskan gRPC-Web), ctx (diteruskan
+// gateway REST) dan header response. Dipasang paling luar di port HTTP.
+func RequestIdHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(utils.RequestIdMetadataKey)
+		if !utils.ValidRequestId(requestId) {
+			requestId = utils.NewRequestId()
+			r.Header.Set(utils.RequestIdMetadataKey, requestId)
+		}
+		w.Header().Set(utils.RequestIdMetadataKey, requestId)
+		next.ServeHTTP(w, r.WithContext(utils.WithRequestId(r.Context(), requestId)))
+	})
+}
@@ -0,0 +1,108 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/audit"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/common"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+type auditMiddleware struct {
+	auditLogger audit.IAuditLogger
+	// cache AuditOptions per method, nil berarti method tidak diaudit
+	options sync.Map
+}
+
+// Middleware mencatat RPC yang diberi option (common.audit) setelah berhasil dijalankan.
+// Selama impersonation semua RPC dicatat, termasuk yang tidak diberi option.
+// Harus dipasang setelah authMiddleware agar actor bisa dibaca dari context.
+func (am *auditMiddleware) Middleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	res, err := handler(ctx, req)
+	if err != nil {
+		return res, err
+	}
+	options := am.auditOptions(info.FullMethod)
+	if options == nil {
+		if claims, claimsErr := jwtentity.GetClaimsFromContext(ctx); claimsErr == nil && claims.IsImpersonated() {
+			options = &common.AuditOptions{
+				Action:     entity.AuditActionImpersonatedRequest,
+				TargetType: entity.AuditTargetUser,
+			}
+		}
+	}
+	if options == nil || !isSuccessResponse(res) {
+		return res, err
+	}
+
+	targetId := targetIdFromRequest(req, options.TargetField)
+	if options.TargetField == "" {
+		if claims, claimsErr := jwtentity.GetClaimsFromContext(ctx); claimsErr == nil {
+			targetId = claims.Subject
+		}
+	}
+	auditErr := am.auditLogger.Record(ctx, audit.Entry{
+		Action:     options.Action,
+		TargetType: options.TargetType,
+		TargetId:   targetId,
+	})
+	if auditErr != nil {
+		logger.FromContext(ctx).Error("failed to record audit log", "error", auditErr)
+	}
+	return res, err
+}
+
+func (am *auditMiddleware) auditOptions(fullMethod string) *common.AuditOptions {
+	if cached, ok := am.options.Load(fullMethod); ok {
+		return cached.(*common.AuditOptions)
+	}
+	var options *common.AuditOptions
+	// "/auth.AuthService/Login" -> "auth.AuthService.Login"
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(fullMethod, "/"), "/", "."))
+	if descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err == nil {
+		if method, ok := descriptor.(protoreflect.MethodDescriptor); ok && proto.HasExtension(method.Options(), common.E_Audit) {
+			options = proto.GetExtension(method.Options(), common.E_Audit).(*common.AuditOptions)
+		}
+	}
+	am.options.Store(fullMethod, options)
+	return options
+}
+
+func isSuccessResponse(res any) bool {
+	withBase, ok := res.(interface{ GetBase() *common.BaseResponse })
+	if !ok {
+		return true
+	}
+	base := withBase.GetBase()
+	return base == nil || (!base.IsError && base.StatusCode < 300)
+}
+
+func targetIdFromRequest(req any, fieldName string) string {
+	if fieldName == "" {
+		return ""
+	}
+	message, ok := req.(proto.Message)
+	if !ok {
+		return ""
+	}
+	reflectMessage := message.ProtoReflect()
+	field := reflectMessage.Descriptor().Fields().ByName(protoreflect.Name(fieldName))
+	if field == nil {
+		return ""
+	}
+	return reflectMessage.Get(field).String()
+}
+
+func NewAuditMiddleware(auditLogger audit.IAuditLogger) *auditMiddleware {
+	return &auditMiddleware{
+		auditLogger: auditLogger,
+	}
+}
@@ -2,41 +2,199 @@ package grpcmiddleware
 
 import (
 	"context"
-	"log"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	gocache "github.com/patrickmn/go-cache"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
 	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/auth"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/metrics"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+// method yang bisa diakses tanpa token
+var publicMethods = map[string]bool{
+	auth.AuthService_Login_FullMethodName:                true,
+	auth.AuthService_Register_FullMethodName:             true,
+	auth.AuthService_GetJwks_FullMethodName:              true,
+	auth.AuthService_ResetPassword_FullMethodName:        true,
+	auth.AuthService_RequestPasswordReset_FullMethodName: true,
+	auth.AuthService_LoginWithOidc_FullMethodName:        true,
+	// token konfirmasi dikirim lewat email, bisa dibuka dari device lain
+	auth.AuthService_ConfirmChangeEmail_FullMethodName: true,
+	// probe Kubernetes tidak membawa token
+	healthpb.Health_Check_FullMethodName: true,
+	healthpb.Health_List_FullMethodName:  true,
+}
+
+// method yang tidak boleh dijalankan dengan token impersonation.
+// Tambahkan method pembayaran di sini ketika service pembayaran sudah ada.
+var impersonationBlockedMethods = map[string]bool{
+	auth.AuthService_ChangePassword_FullMethodName: true,
+	auth.AuthService_ChangeEmail_FullMethodName:    true,
+	auth.AuthService_DeleteAccount_FullMethodName:  true,
+	// admin bisa membaca profil lewat AdminUserService, export berisi seluruh data pribadi user
+	auth.AuthService_ExportMyData_FullMethodName: true,
+}
+
+// method yang mencabut token Bearer pemanggil, tidak berlaku untuk API key
+var apiKeyBlockedMethods = map[string]bool{
+	auth.AuthService_Logout_FullMethodName:        true,
+	auth.AuthService_DeleteAccount_FullMethodName: true,
+}
+
+// last_used_at cukup diperbarui paling sering sekali per interval ini
+const apiKeyLastUsedInterval = time.Minute
 
+// status user di-cache agar tidak query database di setiap request. Perubahan dari
+// replica lain (disable, hapus akun, cabut token) berlaku paling lambat setelah interval ini.
+const userStateCacheTTL = 30 * time.Second
+
+type userState struct {
+	active           bool
+	tokensValidAfter time.Time
+}
 
 type authMiddleware struct {
-	cacheService *gocache.Cache
+	cacheService     *gocache.Cache
+	keyManager       *jwtentity.KeyManager
+	apiKeyRepository repository.IApiKeyRepository
+	authRepository   repository.IAuthRepository
 }
 
 func (am *authMiddleware) Middleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
-	log.Println(info.FullMethod)
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	if apiKey, ok := jwtentity.ParseApiKeyFromContext(ctx); ok {
+		claims, err := am.authenticateApiKey(ctx, apiKey, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(setCallUser(claims.SetToContext(ctx), claims), req)
+	}
 	tokenStr, err := jwtentity.ParseTokenFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if _, ok := am.cacheService.Get(tokenStr); ok {
+	_, loggedOut := am.cacheService.Get(tokenStr)
+	metrics.ObserveCache("logged_out_token", loggedOut)
+	if loggedOut {
 		return nil, utils.UnauthenticatedResponse()
 	}
-	claims, err := jwtentity.GetClaimsFromToken(tokenStr)
+	claims, err := am.keyManager.GetClaimsFromToken(tokenStr)
 	if err != nil {
 		return nil, err
 	}
-	ctx = claims.SetToContext(ctx)
+	revoked, err := am.isTokenRevoked(ctx, claims.Subject, claims.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+	// token impersonation ikut batal jika token admin yang menerbitkannya dicabut
+	if !revoked && claims.IsImpersonated() {
+		revoked, err = am.isTokenRevoked(ctx, claims.Act.Subject, claims.IssuedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if revoked {
+		return nil, utils.UnauthenticatedResponse()
+	}
+	if claims.IsImpersonated() && impersonationBlockedMethods[info.FullMethod] {
+		return nil, utils.PermissionDeniedResponse()
+	}
+	ctx = setCallUser(claims.SetToContext(ctx), claims)
 
 	res, err := handler(ctx, req)
 	return res, err
 }
 
-func NewAuthMiddleware(cacheService *gocache.Cache) *authMiddleware {
+func (am *authMiddleware) authenticateApiKey(ctx context.Context, apiKeyStr string, fullMethod string) (*jwtentity.JwtClaims, error) {
+	prefix, secret, ok := jwtentity.SplitApiKey(apiKeyStr, entity.ApiKeyPrefixTag)
+	if !ok {
+		return nil, utils.UnauthenticatedResponse()
+	}
+	apiKey, err := am.apiKeyRepository.GetApiKeyByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if apiKey == nil || !jwtentity.CompareApiKeySecret(apiKey.SecretHash, secret) || !apiKey.IsActive(now) {
+		return nil, utils.UnauthenticatedResponse()
+	}
+	user, err := am.authRepository.GetUserById(ctx, apiKey.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDisabled || user.RoleCode != entity.UserRoleServiceAccount {
+		return nil, utils.UnauthenticatedResponse()
+	}
+	if apiKeyBlockedMethods[fullMethod] || !jwtentity.ScopeAllows(apiKey.Scopes, fullMethod) {
+		return nil, utils.PermissionDeniedResponse()
+	}
+
+	lastUsedKey := "api_key_last_used:" + apiKey.Id
+	_, recentlyUsed := am.cacheService.Get(lastUsedKey)
+	metrics.ObserveCache("api_key_last_used", recentlyUsed)
+	if !recentlyUsed {
+		err = am.apiKeyRepository.UpdateApiKeyLastUsed(ctx, apiKey.Id, now)
+		if err != nil {
+			return nil, err
+		}
+		am.cacheService.Set(lastUsedKey, true, apiKeyLastUsedInterval)
+	}
+
+	// claims hanya berlaku untuk request ini, tapi diisi lengkap seperti token
+	// agar kode yang membaca iat dan exp tidak perlu membedakan API key
+	expiresAt := now.Add(jwtentity.AccessTokenTTL)
+	if !apiKey.ExpiresAt.IsZero() && apiKey.ExpiresAt.Before(expiresAt) {
+		expiresAt = apiKey.ExpiresAt
+	}
+	claims := &jwtentity.JwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.Id,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Email:    user.Email,
+		FullName: user.FullName,
+		Role:     user.RoleCode,
+	}
+	return claims, nil
+}
+
+// isTokenRevoked menolak token milik user yang sudah dihapus, dinonaktifkan,
+// atau terbit sebelum tokens_valid_after user
+func (am *authMiddleware) isTokenRevoked(ctx context.Context, userId string, issuedAt *jwt.NumericDate) (bool, error) {
+	cacheKey := jwtentity.UserStateCacheKey(userId)
+	cached, found := am.cacheService.Get(cacheKey)
+	metrics.ObserveCache("user_state", found)
+	state, _ := cached.(userState)
+	if !found {
+		user, err := am.authRepository.GetUserById(ctx, userId)
+		if err != nil {
+			return false, err
+		}
+		if user != nil {
+			state = userState{active: !user.IsDisabled, tokensValidAfter: user.TokensValidAfter}
+		}
+		am.cacheService.Set(cacheKey, state, userStateCacheTTL)
+	}
+	if !state.active {
+		return true, nil
+	}
+	return jwtentity.IsIssuedBefore(issuedAt, state.tokensValidAfter), nil
+}
+
+func NewAuthMiddleware(cacheService *gocache.Cache, keyManager *jwtentity.KeyManager, apiKeyRepository repository.IApiKeyRepository, authRepository repository.IAuthRepository) *authMiddleware {
 	return &authMiddleware{
-		cacheService: cacheService,
+		cacheService:     cacheService,
+		keyManager:       keyManager,
+		apiKeyRepository: apiKeyRepository,
+		authRepository:   authRepository,
 	}
 }
@@ -0,0 +1,258 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeApiKeyRepository struct {
+	repository.IApiKeyRepository
+	apiKeys map[string]*entity.ApiKey
+}
+
+func (r *fakeApiKeyRepository) GetApiKeyByPrefix(ctx context.Context, prefix string) (*entity.ApiKey, error) {
+	return r.apiKeys[prefix], nil
+}
+
+func (r *fakeApiKeyRepository) UpdateApiKeyLastUsed(ctx context.Context, apiKeyId string, lastUsedAt time.Time) error {
+	return nil
+}
+
+type fakeAuthRepository struct {
+	repository.IAuthRepository
+	users map[string]*entity.User
+}
+
+func (r *fakeAuthRepository) GetUserById(ctx context.Context, userId string) (*entity.User, error) {
+	return r.users[userId], nil
+}
+
+func TestAuthMiddlewareApiKey(t *testing.T) {
+	keyExpiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	apiKeyRepository := &fakeApiKeyRepository{apiKeys: map[string]*entity.ApiKey{
+		"active": {
+			Id:         "key-1",
+			UserId:     "service-1",
+			SecretHash: jwtentity.HashApiKeySecret("secret"),
+			Scopes:     []string{"/auth.AuthService/*"},
+		},
+		"expiring": {
+			Id:         "key-2",
+			UserId:     "service-1",
+			SecretHash: jwtentity.HashApiKeySecret("secret"),
+			Scopes:     []string{"/auth.AuthService/*"},
+			ExpiresAt:  keyExpiresAt,
+		},
+		"revoked": {
+			Id:         "key-3",
+			UserId:     "service-1",
+			SecretHash: jwtentity.HashApiKeySecret("secret"),
+			Scopes:     []string{"/auth.AuthService/*"},
+			RevokedAt:  time.Now().Add(-time.Minute),
+		},
+	}}
+	authRepository := &fakeAuthRepository{users: map[string]*entity.User{
+		"service-1": {Id: "service-1", RoleCode: entity.UserRoleServiceAccount},
+	}}
+	middleware := NewAuthMiddleware(gocache.New(time.Minute, time.Minute), nil, apiKeyRepository, authRepository)
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		method        string
+		wantCode      codes.Code
+		wantExpiresAt time.Time
+	}{
+		{name: "scoped method", apiKey: "fk_active_secret", method: auth.AuthService_GetProfile_FullMethodName, wantCode: codes.OK},
+		{name: "expiry capped by key", apiKey: "fk_expiring_secret", method: auth.AuthService_GetProfile_FullMethodName, wantCode: codes.OK, wantExpiresAt: keyExpiresAt},
+		{name: "wrong secret", apiKey: "fk_active_wrong", method: auth.AuthService_GetProfile_FullMethodName, wantCode: codes.Unauthenticated},
+		{name: "revoked key", apiKey: "fk_revoked_secret", method: auth.AuthService_GetProfile_FullMethodName, wantCode: codes.Unauthenticated},
+		{name: "malformed key", apiKey: "active_secret", method: auth.AuthService_GetProfile_FullMethodName, wantCode: codes.Unauthenticated},
+		{name: "out of scope", apiKey: "fk_active_secret", method: "/admin.AdminUserService/ListUsers", wantCode: codes.PermissionDenied},
+		{name: "logout blocked", apiKey: "fk_active_secret", method: auth.AuthService_Logout_FullMethodName, wantCode: codes.PermissionDenied},
+		{name: "delete account blocked", apiKey: "fk_active_secret", method: auth.AuthService_DeleteAccount_FullMethodName, wantCode: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// token Bearer palsu ikut dikirim, API key tetap yang dipakai
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				jwtentity.ApiKeyMetadataKey, tt.apiKey,
+				"authorization", "Bearer dummy",
+			))
+			var claims *jwtentity.JwtClaims
+			_, err := middleware.Middleware(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req any) (any, error) {
+				claims, _ = jwtentity.GetClaimsFromContext(ctx)
+				return nil, nil
+			})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v", code, tt.wantCode)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			if claims == nil || claims.Subject != "service-1" {
+				t.Fatalf("claims = %+v", claims)
+			}
+			if claims.IssuedAt == nil || claims.ExpiresAt == nil {
+				t.Fatal("api key claims must have iat and exp")
+			}
+			if !tt.wantExpiresAt.IsZero() && !claims.ExpiresAt.Time.Equal(tt.wantExpiresAt) {
+				t.Errorf("ExpiresAt = %v, want %v", claims.ExpiresAt.Time, tt.wantExpiresAt)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareTokenRevocation(t *testing.T) {
+	keyManager, err := jwtentity.NewKeyManager(jwtentity.KeyManagerConfig{
+		Algorithm:        jwtentity.AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+		Overlap:          time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuedAt := time.Now().Add(-time.Hour)
+	authRepository := &fakeAuthRepository{users: map[string]*entity.User{
+		"active":   {Id: "active"},
+		"disabled": {Id: "disabled", IsDisabled: true},
+		"revoked":  {Id: "revoked", TokensValidAfter: time.Now()},
+		"reissued": {Id: "reissued", TokensValidAfter: issuedAt.Add(-time.Minute)},
+	}}
+
+	tests := []struct {
+		name     string
+		subject  string
+		actor    string
+		wantCode codes.Code
+	}{
+		{name: "active user", subject: "active", wantCode: codes.OK},
+		{name: "disabled user", subject: "disabled", wantCode: codes.Unauthenticated},
+		{name: "deleted user", subject: "deleted", wantCode: codes.Unauthenticated},
+		{name: "token issued before revocation", subject: "revoked", wantCode: codes.Unauthenticated},
+		{name: "token issued after revocation", subject: "reissued", wantCode: codes.OK},
+		{name: "impersonation by active admin", subject: "active", actor: "reissued", wantCode: codes.OK},
+		{name: "impersonation by revoked admin", subject: "active", actor: "revoked", wantCode: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := NewAuthMiddleware(gocache.New(time.Minute, time.Minute), keyManager, &fakeApiKeyRepository{}, authRepository)
+			claims := jwtentity.JwtClaims{RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   tt.subject,
+				IssuedAt:  jwt.NewNumericDate(issuedAt),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			}}
+			if tt.actor != "" {
+				claims.Act = &jwtentity.ActorClaims{Subject: tt.actor}
+			}
+			token, err := keyManager.SignClaims(claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			_, err = middleware.Middleware(ctx, nil, &grpc.UnaryServerInfo{FullMethod: auth.AuthService_GetProfile_FullMethodName}, okHandler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareImpersonation(t *testing.T) {
+	keyManager, err := jwtentity.NewKeyManager(jwtentity.KeyManagerConfig{
+		Algorithm:        jwtentity.AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+		Overlap:          time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authRepository := &fakeAuthRepository{users: map[string]*entity.User{
+		"user-1":  {Id: "user-1"},
+		"admin-1": {Id: "admin-1", RoleCode: entity.UserRoleAdmin},
+	}}
+
+	tests := []struct {
+		name         string
+		method       string
+		impersonated bool
+		wantCode     codes.Code
+	}{
+		{name: "get profile while impersonating", method: auth.AuthService_GetProfile_FullMethodName, impersonated: true, wantCode: codes.OK},
+		{name: "change password while impersonating", method: auth.AuthService_ChangePassword_FullMethodName, impersonated: true, wantCode: codes.PermissionDenied},
+		{name: "change email while impersonating", method: auth.AuthService_ChangeEmail_FullMethodName, impersonated: true, wantCode: codes.PermissionDenied},
+		{name: "delete account while impersonating", method: auth.AuthService_DeleteAccount_FullMethodName, impersonated: true, wantCode: codes.PermissionDenied},
+		{name: "export data while impersonating", method: auth.AuthService_ExportMyData_FullMethodName, impersonated: true, wantCode: codes.PermissionDenied},
+		{name: "export data by user", method: auth.AuthService_ExportMyData_FullMethodName, wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := NewAuthMiddleware(gocache.New(time.Minute, time.Minute), keyManager, &fakeApiKeyRepository{}, authRepository)
+			claims := jwtentity.JwtClaims{RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-1",
+				IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			}}
+			if tt.impersonated {
+				claims.Act = &jwtentity.ActorClaims{Subject: "admin-1"}
+			}
+			token, err := keyManager.SignClaims(claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			_, err = middleware.Middleware(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, okHandler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareForgetUserState(t *testing.T) {
+	keyManager, err := jwtentity.NewKeyManager(jwtentity.KeyManagerConfig{
+		Algorithm:        jwtentity.AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+		Overlap:          time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheService := gocache.New(time.Minute, time.Minute)
+	user := &entity.User{Id: "user-1"}
+	middleware := NewAuthMiddleware(cacheService, keyManager, &fakeApiKeyRepository{}, &fakeAuthRepository{users: map[string]*entity.User{"user-1": user}})
+	token, err := keyManager.SignClaims(jwtentity.JwtClaims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   "user-1",
+		IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	info := &grpc.UnaryServerInfo{FullMethod: auth.AuthService_GetProfile_FullMethodName}
+
+	if _, err := middleware.Middleware(ctx, nil, info, okHandler); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	user.TokensValidAfter = time.Now()
+	if _, err := middleware.Middleware(ctx, nil, info, okHandler); err != nil {
+		t.Fatalf("cached state should still accept token: %v", err)
+	}
+	jwtentity.ForgetUserState(cacheService, "user-1")
+	if _, err := middleware.Middleware(ctx, nil, info, okHandler); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("code = %v, want Unauthenticated after ForgetUserState", status.Code(err))
+	}
+}
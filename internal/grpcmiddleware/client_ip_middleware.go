@@ -0,0 +1,64 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GatewayMetadataKey diisi gateway REST di proses yang sama dengan secret acak
+// yang dibuat saat server start. Request yang membawa secret tersebut melewati
+// satu proxy lagi (gateway) selain TRUSTED_PROXY_HOPS.
+const GatewayMetadataKey = "x-internal-gateway"
+
+type clientIpMiddleware struct {
+	trustedProxyHops int
+	gatewaySecret    string
+}
+
+// Middleware menentukan IP klien sekali per request untuk log, audit dan
+// rate limit. Interceptor pertama di rantai, tidak bergantung pada middleware
+// lain, agar LoggingMiddleware, ipRateLimitMiddleware dan auditMiddleware
+// membaca IP yang sudah diresolve dari ctx.
+func (cm *clientIpMiddleware) Middleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	hops := cm.trustedProxyHops
+	if cm.fromGateway(ctx) {
+		hops++
+	}
+	return handler(utils.WithClientIp(ctx, utils.ResolveClientIp(ctx, hops)), req)
+}
+
+// peer loopback saja tidak cukup, proses lain di host yang sama bisa
+// terhubung langsung dan mengisi x-forwarded-for sendiri
+func (cm *clientIpMiddleware) fromGateway(ctx context.Context) bool {
+	if cm.gatewaySecret == "" {
+		return false
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(GatewayMetadataKey) {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(cm.gatewaySecret)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// gatewaySecret kosong berarti tidak ada gateway REST
+func NewClientIpMiddleware(trustedProxyHops int, gatewaySecret string) *clientIpMiddleware {
+	return &clientIpMiddleware{
+		trustedProxyHops: trustedProxyHops,
+		gatewaySecret:    gatewaySecret,
+	}
+}
+
+// GatewayClientInterceptor menandai panggilan gateway REST ke server gRPC
+// dengan secret yang sama dengan NewClientIpMiddleware
+func GatewayClientInterceptor(gatewaySecret string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, GatewayMetadataKey, gatewaySecret)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
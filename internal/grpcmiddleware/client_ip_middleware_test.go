@@ -0,0 +1,68 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestClientIpMiddleware(t *testing.T) {
+	const secret = "gateway-secret"
+	// gateway menambahkan alamat klien HTTP di akhir x-forwarded-for
+	forwarded := func(pairs ...string) context.Context {
+		md := metadata.Pairs("x-forwarded-for", "1.2.3.4, 198.51.100.9")
+		for i := 0; i < len(pairs); i += 2 {
+			md.Append(pairs[i], pairs[i+1])
+		}
+		return metadata.NewIncomingContext(peerContext("127.0.0.1"), md)
+	}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		trustedProxyHops int
+		gatewaySecret    string
+		want             string
+	}{
+		{name: "gateway counts as hop", ctx: forwarded(GatewayMetadataKey, secret), gatewaySecret: secret, want: "198.51.100.9"},
+		{name: "gateway behind proxy", ctx: forwarded(GatewayMetadataKey, secret), trustedProxyHops: 1, gatewaySecret: secret, want: "1.2.3.4"},
+		// proses lain di host yang sama tidak dianggap gateway
+		{name: "loopback without secret", ctx: forwarded(), gatewaySecret: secret, want: "127.0.0.1"},
+		{name: "wrong secret", ctx: forwarded(GatewayMetadataKey, "guess"), gatewaySecret: secret, want: "127.0.0.1"},
+		{name: "gateway disabled", ctx: forwarded(GatewayMetadataKey, ""), want: "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := func(ctx context.Context, req any) (any, error) {
+				got = utils.ClientIpFromContext(ctx)
+				return "ok", nil
+			}
+			middleware := NewClientIpMiddleware(tt.trustedProxyHops, tt.gatewaySecret)
+			if _, err := middleware.Middleware(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler); err != nil {
+				t.Fatalf("Middleware() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("client ip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatewayClientInterceptor(t *testing.T) {
+	var got []string
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		got = md.Get(GatewayMetadataKey)
+		return nil
+	}
+	if err := GatewayClientInterceptor("gateway-secret")(context.Background(), testMethod, nil, nil, nil, invoker); err != nil {
+		t.Fatalf("interceptor error = %v", err)
+	}
+	if len(got) != 1 || got[0] != "gateway-secret" {
+		t.Errorf("%s = %q, want gateway secret", GatewayMetadataKey, got)
+	}
+}
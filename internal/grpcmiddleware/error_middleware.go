@@ -2,19 +2,29 @@ package grpcmiddleware
 
 import (
 	"context"
-	"log"
 	"runtime/debug"
 
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// error dengan code ini memang ditujukan ke klien dan diteruskan apa adanya
+var clientErrorCodes = map[codes.Code]bool{
+	codes.Unauthenticated:    true,
+	codes.PermissionDenied:   true,
+	codes.ResourceExhausted:  true,
+	codes.InvalidArgument:    true,
+	codes.FailedPrecondition: true,
+	codes.Aborted:            true,
+}
+
 func ErrorMiddleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Println(r)
-			debug.PrintStack() // stack trace
+			logger.FromContext(ctx).Error("panic recovered", "panic", r, "stack", string(debug.Stack()))
 			err = status.Errorf(
 				codes.Internal,
 				"internal server error: %v", r,
@@ -23,13 +33,11 @@ func ErrorMiddleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, h
 	}()
 	res, err := handler(ctx, req)
 	if err != nil {
-		log.Println(err)
-
-		if e, ok := status.FromError(err); ok {
-			if e.Code() == codes.Unauthenticated {
-				return nil, err
-			}
+		if e, ok := status.FromError(err); ok && clientErrorCodes[e.Code()] {
+			return nil, err
 		}
+		// error asli hanya ditulis ke log, klien menerima pesan umum
+		logger.FromContext(ctx).Error("internal error", "error", err)
 		return nil, status.Error(codes.Internal, "Internal Server Error")
 	}
 	return res, err
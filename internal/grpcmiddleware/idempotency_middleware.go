@@ -0,0 +1,215 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/address"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/common"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	IdempotencyKeyMetadataKey = "idempotency-key"
+	// diisi "true" jika response diambil dari request sebelumnya
+	IdempotentReplayedMetadataKey = "idempotent-replayed"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// idempotentMethods adalah method yang sudah diperiksa tidak punya efek di luar
+// database (email, cache), karena handler dijalankan dalam transaksi yang bisa
+// di-rollback. Method baru dengan option (common.idempotent) harus ditambahkan
+// di sini, jika tidak server gagal start.
+var idempotentMethods = map[string]bool{
+	address.AddressService_CreateAddress_FullMethodName: true,
+}
+
+// errIdempotencyRollback membatalkan transaksi saat handler mengembalikan response gagal
+var errIdempotencyRollback = errors.New("idempotent request did not succeed")
+
+type idempotencyMiddleware struct {
+	txManager             database.ITransactionManager
+	idempotencyRepository repository.IIdempotencyRepository
+	// cache tipe response per method, nil berarti method tidak diberi option
+	responseTypes sync.Map
+}
+
+// Middleware menjalankan RPC yang diberi option (common.idempotent) paling
+// banyak sekali per idempotency-key: retry dengan request yang sama mendapat
+// response yang tersimpan, request berbeda dengan key yang sama ditolak.
+// Dipasang setelah authMiddleware agar key dipisah per user, dan sebelum
+// auditMiddleware agar response yang diputar ulang tidak tercatat dua kali.
+// Handler dijalankan dalam transaksi, jadi method yang diberi option harus
+// terdaftar di idempotentMethods, lihat CheckMethods.
+func (im *idempotencyMiddleware) Middleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	responseType := im.responseType(info.FullMethod)
+	key := idempotencyKeyFromContext(ctx)
+	message, ok := req.(proto.Message)
+	if responseType == nil || key == "" || !ok {
+		return handler(ctx, req)
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, status.Error(codes.InvalidArgument, "idempotency-key is too long")
+	}
+	requestHash, err := hashRequest(info.FullMethod, message)
+	if err != nil {
+		return nil, err
+	}
+	// key dari pemanggil anonim tidak bisa dipisah per pemanggil, klien lain bisa
+	// mendapat response milik orang lain dengan menebak key yang sama
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil || claims.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "idempotency-key requires an authenticated request")
+	}
+	scope := claims.Subject
+
+	now := time.Now()
+	reserved, err := im.idempotencyRepository.ReserveIdempotencyKey(ctx, &entity.IdempotencyKey{
+		Scope:       scope,
+		Method:      info.FullMethod,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(entity.IdempotencyKeyLockTimeout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return im.replay(ctx, scope, info.FullMethod, key, requestHash, responseType)
+	}
+
+	// response disimpan dalam transaksi yang sama dengan perubahan dari handler, jadi
+	// request tidak pernah berhasil tanpa response tersimpan. Serializable karena
+	// isolation level transaksi di dalam handler diabaikan saat menjadi savepoint.
+	var res any
+	err = im.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		res, err = handler(ctx, req)
+		if err != nil {
+			return err
+		}
+		// response gagal tetap di-rollback agar perubahan sebagian dari handler tidak tersimpan
+		if !isSuccessResponse(res) {
+			return errIdempotencyRollback
+		}
+		response, err := proto.Marshal(res.(proto.Message))
+		if err != nil {
+			return err
+		}
+		return im.idempotencyRepository.CompleteIdempotencyKey(ctx, scope, info.FullMethod, key, response, time.Now().Add(entity.IdempotencyKeyTTL))
+	}, database.WithIsolation(sql.LevelSerializable))
+	if err != nil {
+		// tidak ada perubahan yang tersimpan, klien boleh mencoba lagi dengan key yang sama
+		im.release(ctx, scope, info.FullMethod, key)
+	}
+	if err != nil && !errors.Is(err, errIdempotencyRollback) {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CheckMethods dipanggil saat startup setelah semua service didaftarkan, agar
+// method dengan option (common.idempotent) yang belum diperiksa tidak terpasang
+func (im *idempotencyMiddleware) CheckMethods(services map[string]grpc.ServiceInfo) error {
+	for serviceName, service := range services {
+		for _, method := range service.Methods {
+			fullMethod := "/" + serviceName + "/" + method.Name
+			if im.responseType(fullMethod) != nil && !idempotentMethods[fullMethod] {
+				return fmt.Errorf("method %s has option (common.idempotent) but is not in the idempotent method allowlist", fullMethod)
+			}
+		}
+	}
+	return nil
+}
+
+// release tetap melepas key walaupun klien sudah memutus koneksi
+func (im *idempotencyMiddleware) release(ctx context.Context, scope string, method string, key string) {
+	if err := im.idempotencyRepository.DeleteIdempotencyKey(context.WithoutCancel(ctx), scope, method, key); err != nil {
+		logger.FromContext(ctx).Warn("failed to release idempotency key", "error", err)
+	}
+}
+
+func (im *idempotencyMiddleware) replay(ctx context.Context, scope string, method string, key string, requestHash string, responseType protoreflect.MessageType) (any, error) {
+	existing, err := im.idempotencyRepository.GetIdempotencyKey(ctx, scope, method, key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.RequestHash != requestHash {
+		return nil, status.Error(codes.FailedPrecondition, "idempotency-key was already used with a different request")
+	}
+	if existing == nil || existing.Response == nil {
+		return nil, status.Error(codes.Aborted, "a request with this idempotency-key is still being processed")
+	}
+	res := responseType.New().Interface()
+	if err := proto.Unmarshal(existing.Response, res); err != nil {
+		return nil, err
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedMetadataKey, "true"))
+	return res, nil
+}
+
+func (im *idempotencyMiddleware) responseType(fullMethod string) protoreflect.MessageType {
+	if cached, ok := im.responseTypes.Load(fullMethod); ok {
+		responseType, _ := cached.(protoreflect.MessageType)
+		return responseType
+	}
+	var responseType protoreflect.MessageType
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(fullMethod, "/"), "/", "."))
+	if descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err == nil {
+		if method, ok := descriptor.(protoreflect.MethodDescriptor); ok && proto.GetExtension(method.Options(), common.E_Idempotent).(bool) {
+			responseType, _ = protoregistry.GlobalTypes.FindMessageByName(method.Output().FullName())
+		}
+	}
+	im.responseTypes.Store(fullMethod, responseType)
+	return responseType
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(IdempotencyKeyMetadataKey); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// hashRequest memakai marshal deterministik agar urutan map tidak mengubah hash
+func hashRequest(fullMethod string, message proto.Message) (string, error) {
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(fullMethod))
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func NewIdempotencyMiddleware(txManager database.ITransactionManager, idempotencyRepository repository.IIdempotencyRepository) *idempotencyMiddleware {
+	return &idempotencyMiddleware{
+		txManager:             txManager,
+		idempotencyRepository: idempotencyRepository,
+	}
+}
@@ -0,0 +1,335 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/address"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeIdempotencyRepository struct {
+	repository.IIdempotencyRepository
+	keys        map[string]*entity.IdempotencyKey
+	completeErr error
+}
+
+func newFakeIdempotencyRepository() *fakeIdempotencyRepository {
+	return &fakeIdempotencyRepository{keys: make(map[string]*entity.IdempotencyKey)}
+}
+
+func idempotencyMapKey(scope string, method string, key string) string {
+	return scope + "|" + method + "|" + key
+}
+
+func (r *fakeIdempotencyRepository) ReserveIdempotencyKey(ctx context.Context, idempotencyKey *entity.IdempotencyKey) (bool, error) {
+	mapKey := idempotencyMapKey(idempotencyKey.Scope, idempotencyKey.Method, idempotencyKey.Key)
+	if existing, ok := r.keys[mapKey]; ok && existing.ExpiresAt.After(idempotencyKey.CreatedAt) {
+		return false, nil
+	}
+	reserved := *idempotencyKey
+	r.keys[mapKey] = &reserved
+	return true, nil
+}
+
+func (r *fakeIdempotencyRepository) GetIdempotencyKey(ctx context.Context, scope string, method string, key string) (*entity.IdempotencyKey, error) {
+	return r.keys[idempotencyMapKey(scope, method, key)], nil
+}
+
+func (r *fakeIdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, scope string, method string, key string, response []byte, expiresAt time.Time) error {
+	if r.completeErr != nil {
+		return r.completeErr
+	}
+	existing := r.keys[idempotencyMapKey(scope, method, key)]
+	existing.Response = response
+	existing.ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *fakeIdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, scope string, method string, key string) error {
+	delete(r.keys, idempotencyMapKey(scope, method, key))
+	return nil
+}
+
+// fakeTransactionManager mencatat hasil transaksi terluar
+type fakeTransactionManager struct {
+	commits   int
+	rollbacks int
+}
+
+func (m *fakeTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...database.TxOption) error {
+	if err := fn(ctx); err != nil {
+		m.rollbacks++
+		return err
+	}
+	m.commits++
+	return nil
+}
+
+func idempotentContext(userId string, key string) context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyMetadataKey, key))
+	if userId == "" {
+		return ctx
+	}
+	claims := &jwtentity.JwtClaims{}
+	claims.Subject = userId
+	return claims.SetToContext(ctx)
+}
+
+func createAddressRequest(label string) *address.CreateAddressRequest {
+	return &address.CreateAddressRequest{Address: &address.AddressInput{Label: label}}
+}
+
+// existingKey membuat key milik request sebelumnya, response nil berarti masih berjalan
+func existingKey(t *testing.T, scope string, req *address.CreateAddressRequest, response proto.Message) *entity.IdempotencyKey {
+	t.Helper()
+	requestHash, err := hashRequest(address.AddressService_CreateAddress_FullMethodName, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idempotencyKey := &entity.IdempotencyKey{
+		Scope:       scope,
+		Method:      address.AddressService_CreateAddress_FullMethodName,
+		Key:         "key-1",
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(entity.IdempotencyKeyLockTimeout),
+	}
+	if response != nil {
+		idempotencyKey.Response, err = proto.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idempotencyKey.ExpiresAt = time.Now().Add(entity.IdempotencyKeyTTL)
+	}
+	return idempotencyKey
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	const (
+		keyNone      = "none"
+		keyPending   = "pending"
+		keyCompleted = "completed"
+	)
+	createAddress := &grpc.UnaryServerInfo{FullMethod: address.AddressService_CreateAddress_FullMethodName}
+	listAddresses := &grpc.UnaryServerInfo{FullMethod: address.AddressService_ListAddresses_FullMethodName}
+	successHandler := func(ctx context.Context, req any) (any, error) {
+		return &address.CreateAddressResponse{Base: utils.SuccessResponse("Create Address Success"), AddressId: "address-1"}, nil
+	}
+	storedResponse := &address.CreateAddressResponse{Base: utils.SuccessResponse("Create Address Success"), AddressId: "stored-address"}
+
+	tests := []struct {
+		name        string
+		existing    *entity.IdempotencyKey
+		ctx         context.Context
+		info        *grpc.UnaryServerInfo
+		req         any
+		handler     grpc.UnaryHandler
+		completeErr error
+
+		wantCode          codes.Code
+		wantHandlerCalled bool
+		wantAddressId     string
+		// status key user-1 setelah request
+		wantKey       string
+		wantCommits   int
+		wantRollbacks int
+	}{
+		{
+			name:              "without key",
+			ctx:               idempotentContext("user-1", ""),
+			info:              createAddress,
+			req:               createAddressRequest("Rumah"),
+			handler:           successHandler,
+			wantHandlerCalled: true,
+			wantAddressId:     "address-1",
+			wantKey:           keyNone,
+		},
+		{
+			name:              "method without option",
+			ctx:               idempotentContext("user-1", "key-1"),
+			info:              listAddresses,
+			req:               &address.ListAddressesRequest{},
+			handler:           func(ctx context.Context, req any) (any, error) { return &address.ListAddressesResponse{}, nil },
+			wantHandlerCalled: true,
+			wantKey:           keyNone,
+		},
+		{
+			name:     "anonymous caller rejected",
+			ctx:      idempotentContext("", "key-1"),
+			info:     createAddress,
+			req:      createAddressRequest("Rumah"),
+			handler:  successHandler,
+			wantCode: codes.InvalidArgument,
+			wantKey:  keyNone,
+		},
+		{
+			name:              "first request stores response in same transaction",
+			ctx:               idempotentContext("user-1", "key-1"),
+			info:              createAddress,
+			req:               createAddressRequest("Rumah"),
+			handler:           successHandler,
+			wantHandlerCalled: true,
+			wantAddressId:     "address-1",
+			wantKey:           keyCompleted,
+			wantCommits:       1,
+		},
+		{
+			name:          "retry replays stored response",
+			existing:      existingKey(t, "user-1", createAddressRequest("Rumah"), storedResponse),
+			ctx:           idempotentContext("user-1", "key-1"),
+			info:          createAddress,
+			req:           createAddressRequest("Rumah"),
+			handler:       successHandler,
+			wantAddressId: "stored-address",
+			wantKey:       keyCompleted,
+		},
+		{
+			name:     "same key with different request",
+			existing: existingKey(t, "user-1", createAddressRequest("Rumah"), storedResponse),
+			ctx:      idempotentContext("user-1", "key-1"),
+			info:     createAddress,
+			req:      createAddressRequest("Kantor"),
+			handler:  successHandler,
+			wantCode: codes.FailedPrecondition,
+			wantKey:  keyCompleted,
+		},
+		{
+			name:     "first request still running",
+			existing: existingKey(t, "user-1", createAddressRequest("Rumah"), nil),
+			ctx:      idempotentContext("user-1", "key-1"),
+			info:     createAddress,
+			req:      createAddressRequest("Rumah"),
+			handler:  successHandler,
+			wantCode: codes.Aborted,
+			wantKey:  keyPending,
+		},
+		{
+			name:              "same key from another user is independent",
+			existing:          existingKey(t, "user-2", createAddressRequest("Rumah"), storedResponse),
+			ctx:               idempotentContext("user-1", "key-1"),
+			info:              createAddress,
+			req:               createAddressRequest("Rumah"),
+			handler:           successHandler,
+			wantHandlerCalled: true,
+			wantAddressId:     "address-1",
+			wantKey:           keyCompleted,
+			wantCommits:       1,
+		},
+		{
+			name:              "handler error releases key",
+			ctx:               idempotentContext("user-1", "key-1"),
+			info:              createAddress,
+			req:               createAddressRequest("Rumah"),
+			handler:           func(ctx context.Context, req any) (any, error) { return nil, errors.New("database down") },
+			wantCode:          codes.Unknown,
+			wantHandlerCalled: true,
+			wantKey:           keyNone,
+			wantRollbacks:     1,
+		},
+		{
+			name: "bad request response releases key",
+			ctx:  idempotentContext("user-1", "key-1"),
+			info: createAddress,
+			req:  createAddressRequest("Rumah"),
+			handler: func(ctx context.Context, req any) (any, error) {
+				return &address.CreateAddressResponse{Base: utils.BadRequestResponse("Maximum addresses")}, nil
+			},
+			wantHandlerCalled: true,
+			wantKey:           keyNone,
+			wantRollbacks:     1,
+		},
+		{
+			name:              "failed to store response rolls back mutation",
+			ctx:               idempotentContext("user-1", "key-1"),
+			info:              createAddress,
+			req:               createAddressRequest("Rumah"),
+			handler:           successHandler,
+			completeErr:       errors.New("connection reset"),
+			wantCode:          codes.Unknown,
+			wantHandlerCalled: true,
+			wantKey:           keyNone,
+			wantRollbacks:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeIdempotencyRepository()
+			repo.completeErr = tt.completeErr
+			if tt.existing != nil {
+				repo.keys[idempotencyMapKey(tt.existing.Scope, tt.existing.Method, tt.existing.Key)] = tt.existing
+			}
+			txManager := &fakeTransactionManager{}
+			middleware := NewIdempotencyMiddleware(txManager, repo)
+
+			handlerCalled := false
+			res, err := middleware.Middleware(tt.ctx, tt.req, tt.info, func(ctx context.Context, req any) (any, error) {
+				handlerCalled = true
+				return tt.handler(ctx, req)
+			})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v (%v), want %v", code, err, tt.wantCode)
+			}
+			if tt.wantCode == codes.OK && res == nil {
+				t.Errorf("response = nil, want handler response")
+			}
+			if handlerCalled != tt.wantHandlerCalled {
+				t.Errorf("handler called = %v, want %v", handlerCalled, tt.wantHandlerCalled)
+			}
+			if tt.wantAddressId != "" {
+				if got := res.(*address.CreateAddressResponse).AddressId; got != tt.wantAddressId {
+					t.Errorf("AddressId = %q, want %q", got, tt.wantAddressId)
+				}
+			}
+			keyState := keyNone
+			if stored := repo.keys[idempotencyMapKey("user-1", address.AddressService_CreateAddress_FullMethodName, "key-1")]; stored != nil {
+				keyState = keyPending
+				if stored.Response != nil {
+					keyState = keyCompleted
+				}
+			}
+			if keyState != tt.wantKey {
+				t.Errorf("key state = %s, want %s", keyState, tt.wantKey)
+			}
+			if txManager.commits != tt.wantCommits || txManager.rollbacks != tt.wantRollbacks {
+				t.Errorf("commits = %d rollbacks = %d, want %d and %d", txManager.commits, txManager.rollbacks, tt.wantCommits, tt.wantRollbacks)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareCheckMethods(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist map[string]bool
+		wantErr   bool
+	}{
+		{name: "all idempotent methods allowed", allowlist: idempotentMethods},
+		{name: "idempotent method missing from allowlist", allowlist: map[string]bool{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := idempotentMethods
+			idempotentMethods = tt.allowlist
+			defer func() { idempotentMethods = original }()
+
+			middleware := NewIdempotencyMiddleware(&fakeTransactionManager{}, newFakeIdempotencyRepository())
+			err := middleware.CheckMethods(map[string]grpc.ServiceInfo{
+				address.AddressService_ServiceDesc.ServiceName: {Methods: []grpc.MethodInfo{{Name: "CreateAddress"}, {Name: "ListAddresses"}}},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckMethods() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
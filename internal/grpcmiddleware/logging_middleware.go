@@ -0,0 +1,107 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/common"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// user id baru diketahui di auth middleware (setelah interceptor ini),
+// jadi dititipkan lewat pointer di ctx agar ikut tertulis di log akhir request
+type callFields struct {
+	userId         string
+	impersonatorId string
+}
+
+type callFieldsKey struct{}
+
+type baseResponse interface {
+	GetBase() *common.BaseResponse
+}
+
+// LoggingMiddleware menulis satu baris log per request (method, code, latency,
+// user id, request id) dan menyimpan logger request ke ctx untuk dipakai service.
+// Dipasang setelah clientIpMiddleware dan RequestIdMiddleware yang mengisi IP
+// dan request id di ctx, dan sebelum ErrorMiddleware agar code yang tercatat
+// adalah code akhir ke klien.
+func LoggingMiddleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	requestLogger := logger.FromContext(ctx).With(
+		slog.String("method", info.FullMethod),
+		slog.String("request_id", utils.RequestIdFromContext(ctx)),
+	)
+	// span RPC dibuat stats handler sebelum interceptor berjalan
+	if traceId := tracing.TraceIdFromContext(ctx); traceId != "" {
+		requestLogger = requestLogger.With(slog.String("trace_id", traceId))
+	}
+	fields := &callFields{}
+	ctx = context.WithValue(ctx, callFieldsKey{}, fields)
+	ctx = logger.WithContext(ctx, requestLogger)
+
+	res, err := handler(ctx, req)
+
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		slog.String("ip_address", utils.ClientIpFromContext(ctx)),
+	}
+	if fields.userId != "" {
+		attrs = append(attrs, slog.String("user_id", fields.userId))
+	}
+	if fields.impersonatorId != "" {
+		attrs = append(attrs, slog.String("impersonator_id", fields.impersonatorId))
+	}
+	if base, ok := res.(baseResponse); ok && base.GetBase() != nil {
+		attrs = append(attrs, slog.Int64("status_code", base.GetBase().GetStatusCode()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	level := logLevel(code)
+	// payload hanya ditulis saat gagal atau level debug, field sensitif disamarkan
+	if message, ok := req.(proto.Message); ok && (err != nil || requestLogger.Enabled(ctx, slog.LevelDebug)) {
+		attrs = append(attrs, slog.Any("request", logger.Redact(message)))
+	}
+	requestLogger.LogAttrs(ctx, level, "grpc request", attrs...)
+	return res, err
+}
+
+// setCallUser dipanggil setelah autentikasi agar log request dan logger di
+// service membawa user id
+func setCallUser(ctx context.Context, claims *jwtentity.JwtClaims) context.Context {
+	attrs := []any{slog.String("user_id", claims.Subject)}
+	fields, _ := ctx.Value(callFieldsKey{}).(*callFields)
+	if fields != nil {
+		fields.userId = claims.Subject
+	}
+	if claims.IsImpersonated() {
+		attrs = append(attrs, slog.String("impersonator_id", claims.Act.Subject))
+		if fields != nil {
+			fields.impersonatorId = claims.Act.Subject
+		}
+	}
+	return logger.WithContext(ctx, logger.FromContext(ctx).With(attrs...))
+}
+
+func logLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.ResourceExhausted, codes.FailedPrecondition, codes.Unauthenticated:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
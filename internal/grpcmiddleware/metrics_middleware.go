@@ -0,0 +1,26 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsMiddleware mencatat jumlah dan latency RPC. Dipasang sebelum
+// ErrorMiddleware agar code yang tercatat sama dengan yang diterima klien.
+func MetricsMiddleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+
+	// status_code kosong untuk response tanpa base atau RPC yang gagal
+	statusCode := ""
+	if base, ok := res.(baseResponse); ok && base.GetBase() != nil {
+		statusCode = strconv.FormatInt(base.GetBase().GetStatusCode(), 10)
+	}
+	metrics.ObserveRpc(info.FullMethod, status.Code(err).String(), statusCode, time.Since(start).Seconds())
+	return res, err
+}
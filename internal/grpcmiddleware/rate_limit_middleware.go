@@ -0,0 +1,113 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/metrics"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// jumlah detik sampai klien boleh mencoba lagi, dikirim saat ResourceExhausted
+const RetryAfterMetadataKey = "retry-after"
+
+// probe Kubernetes datang terus-menerus dari IP yang sama
+var rateLimitExemptMethods = map[string]bool{
+	healthpb.Health_Check_FullMethodName: true,
+	healthpb.Health_List_FullMethodName:  true,
+}
+
+type rateLimitMiddleware struct {
+	store        ratelimit.IStore
+	defaultLimit ratelimit.Limit
+	methodLimits map[string]ratelimit.Limit
+}
+
+// Middleware membatasi request per method dan per pemanggil. Dipasang setelah
+// auth middleware agar user id dan API key yang dipakai sudah terverifikasi.
+func (rl *rateLimitMiddleware) Middleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if rateLimitExemptMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	limit, ok := rl.methodLimits[info.FullMethod]
+	if !ok {
+		limit = rl.defaultLimit
+	}
+	if err := take(ctx, rl.store, info.FullMethod+"|"+callerKey(ctx), limit, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+type ipRateLimitMiddleware struct {
+	store ratelimit.IStore
+	limit ratelimit.Limit
+}
+
+// Middleware membatasi seluruh request per IP klien. Dipasang sebelum auth
+// middleware agar token atau API key palsu dalam jumlah besar ikut dibatasi.
+func (rl *ipRateLimitMiddleware) Middleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if rateLimitExemptMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	if err := take(ctx, rl.store, "ip|"+utils.ClientIpFromContext(ctx), rl.limit, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func take(ctx context.Context, store ratelimit.IStore, key string, limit ratelimit.Limit, method string) error {
+	result, err := store.Take(ctx, key, limit)
+	if err != nil {
+		// store bersama yang bermasalah tidak boleh mematikan seluruh API
+		logger.FromContext(ctx).Warn("rate limit store failed", "error", err)
+		return nil
+	}
+	if !result.Allowed {
+		retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+		grpc.SetHeader(ctx, metadata.Pairs(RetryAfterMetadataKey, strconv.Itoa(retryAfter)))
+		metrics.RateLimited(method)
+		return status.Error(codes.ResourceExhausted, "too many requests, please retry later")
+	}
+	return nil
+}
+
+// callerKey memakai API key atau user id jika request sudah diautentikasi,
+// selain itu IP klien hasil ResolveClientIp (method publik seperti Login dan Register)
+func callerKey(ctx context.Context) string {
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil || claims.Subject == "" {
+		return "ip:" + utils.ClientIpFromContext(ctx)
+	}
+	if apiKey, ok := jwtentity.ParseApiKeyFromContext(ctx); ok {
+		if prefix, _, ok := jwtentity.SplitApiKey(apiKey, entity.ApiKeyPrefixTag); ok {
+			return "api_key:" + prefix
+		}
+	}
+	return "user:" + claims.Subject
+}
+
+func NewRateLimitMiddleware(store ratelimit.IStore, defaultLimit ratelimit.Limit, methodLimits map[string]ratelimit.Limit) *rateLimitMiddleware {
+	return &rateLimitMiddleware{
+		store:        store,
+		defaultLimit: defaultLimit,
+		methodLimits: methodLimits,
+	}
+}
+
+func NewIpRateLimitMiddleware(store ratelimit.IStore, limit ratelimit.Limit) *ipRateLimitMiddleware {
+	return &ipRateLimitMiddleware{
+		store: store,
+		limit: limit,
+	}
+}
@@ -0,0 +1,118 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/auth.AuthService/Login"
+
+func okHandler(ctx context.Context, req any) (any, error) {
+	return "ok", nil
+}
+
+func peerContext(ip string) context.Context {
+	addr := &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+}
+
+func TestCallerKey(t *testing.T) {
+	userClaims := &jwtentity.JwtClaims{}
+	userClaims.Subject = "user-1"
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "anonymous uses peer ip", ctx: peerContext("203.0.113.7"), want: "ip:203.0.113.7"},
+		{
+			name: "anonymous ignores spoofed forwarded for",
+			ctx:  metadata.NewIncomingContext(peerContext("203.0.113.7"), metadata.Pairs("x-forwarded-for", "1.2.3.4")),
+			want: "ip:203.0.113.7",
+		},
+		{name: "anonymous uses resolved ip", ctx: utils.WithClientIp(peerContext("10.0.0.2"), "198.51.100.9"), want: "ip:198.51.100.9"},
+		{name: "authenticated user", ctx: userClaims.SetToContext(peerContext("203.0.113.7")), want: "user:user-1"},
+		{
+			name: "api key",
+			ctx:  userClaims.SetToContext(metadata.NewIncomingContext(peerContext("203.0.113.7"), metadata.Pairs(jwtentity.ApiKeyMetadataKey, "fk_abcd1234_secret"))),
+			want: "api_key:abcd1234",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callerKey(tt.ctx); got != tt.want {
+				t.Errorf("callerKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	middleware := NewRateLimitMiddleware(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 100, Period: time.Second}, map[string]ratelimit.Limit{
+		testMethod: {Requests: 2, Period: time.Minute},
+	})
+	tests := []struct {
+		name     string
+		method   string
+		ip       string
+		wantCode codes.Code
+	}{
+		{name: "first", method: testMethod, ip: "203.0.113.7", wantCode: codes.OK},
+		{name: "second", method: testMethod, ip: "203.0.113.7", wantCode: codes.OK},
+		{name: "over method limit", method: testMethod, ip: "203.0.113.7", wantCode: codes.ResourceExhausted},
+		{name: "other ip has own bucket", method: testMethod, ip: "203.0.113.8", wantCode: codes.OK},
+		{name: "other method uses default", method: "/auth.AuthService/GetProfile", ip: "203.0.113.7", wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := middleware.Middleware(peerContext(tt.ip), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, okHandler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestIpRateLimitMiddleware(t *testing.T) {
+	middleware := NewIpRateLimitMiddleware(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 2, Period: time.Minute})
+	tests := []struct {
+		name     string
+		method   string
+		ip       string
+		wantCode codes.Code
+	}{
+		{name: "first", method: testMethod, ip: "203.0.113.7", wantCode: codes.OK},
+		{name: "shared across methods", method: "/auth.AuthService/GetProfile", ip: "203.0.113.7", wantCode: codes.OK},
+		{name: "over ip limit", method: "/auth.AuthService/Logout", ip: "203.0.113.7", wantCode: codes.ResourceExhausted},
+		{name: "health check exempt", method: healthpb.Health_Check_FullMethodName, ip: "203.0.113.7", wantCode: codes.OK},
+		{name: "other ip", method: testMethod, ip: "203.0.113.8", wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled := false
+			_, err := middleware.Middleware(peerContext(tt.ip), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req any) (any, error) {
+				handlerCalled = true
+				return nil, nil
+			})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("code = %v, want %v", code, tt.wantCode)
+			}
+			if handlerCalled != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %v", handlerCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package grpcmiddleware
+
+import (
+	"context"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIdMiddleware memakai x-request-id dari klien atau membuat yang baru,
+// menyimpannya di ctx (dipakai log dan audit) dan mengembalikannya di header,
+// trailer serta BaseResponse. Dipasang setelah clientIpMiddleware dan sebelum
+// LoggingMiddleware, agar request id sudah ada di ctx saat logger request dibuat.
+func RequestIdMiddleware(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	requestId := utils.RequestIdFromContext(ctx)
+	if requestId == "" {
+		requestId = utils.NewRequestId()
+	}
+	ctx = utils.WithRequestId(ctx, requestId)
+
+	md := metadata.Pairs(utils.RequestIdMetadataKey, requestId)
+	grpc.SetHeader(ctx, md)
+	grpc.SetTrailer(ctx, md)
+
+	res, err := handler(ctx, req)
+	if base, ok := res.(baseResponse); ok && base.GetBase() != nil {
+		base.GetBase().RequestId = requestId
+	}
+	return res, err
+}
+
+// RequestIdClientInterceptor meneruskan request id di ctx ke panggilan gRPC keluar
+func RequestIdClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if requestId := utils.RequestIdFromContext(ctx); requestId != "" {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if len(md.Get(utils.RequestIdMetadataKey)) == 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, utils.RequestIdMetadataKey, requestId)
+		}
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
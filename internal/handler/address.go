@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/service"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/address"
+)
+
+type addressHandler struct {
+	address.UnimplementedAddressServiceServer
+	addressService service.IAddressService
+}
+
+func (s *addressHandler) CreateAddress(ctx context.Context, request *address.CreateAddressRequest) (*address.CreateAddressResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &address.CreateAddressResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.addressService.CreateAddress(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *addressHandler) ListAddresses(ctx context.Context, request *address.ListAddressesRequest) (*address.ListAddressesResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &address.ListAddressesResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.addressService.ListAddresses(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *addressHandler) GetAddress(ctx context.Context, request *address.GetAddressRequest) (*address.GetAddressResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &address.GetAddressResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.addressService.GetAddress(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *addressHandler) UpdateAddress(ctx context.Context, request *address.UpdateAddressRequest) (*address.UpdateAddressResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &address.UpdateAddressResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.addressService.UpdateAddress(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *addressHandler) DeleteAddress(ctx context.Context, request *address.DeleteAddressRequest) (*address.DeleteAddressResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &address.DeleteAddressResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.addressService.DeleteAddress(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func NewAddressHandler(addressService service.IAddressService) *addressHandler {
+	return &addressHandler{
+		addressService: addressService,
+	}
+}
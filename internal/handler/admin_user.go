@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/service"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/admin"
+)
+
+type adminUserHandler struct {
+	admin.UnimplementedAdminUserServiceServer
+	adminUserService service.IAdminUserService
+}
+
+func (s *adminUserHandler) ListUsers(ctx context.Context, request *admin.ListUsersRequest) (*admin.ListUsersResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.ListUsersResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.adminUserService.ListUsers(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *adminUserHandler) GetUser(ctx context.Context, request *admin.GetUserRequest) (*admin.GetUserResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.GetUserResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.adminUserService.GetUser(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *adminUserHandler) ChangeUserRole(ctx context.Context, request *admin.ChangeUserRoleRequest) (*admin.ChangeUserRoleResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.ChangeUserRoleResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.adminUserService.ChangeUserRole(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *adminUserHandler) DisableUser(ctx context.Context, request *admin.DisableUserRequest) (*admin.DisableUserResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.DisableUserResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.adminUserService.DisableUser(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *adminUserHandler) EnableUser(ctx context.Context, request *admin.EnableUserRequest) (*admin.EnableUserResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.EnableUserResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.adminUserService.EnableUser(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *adminUserHandler) ForcePasswordReset(ctx context.Context, request *admin.ForcePasswordResetRequest) (*admin.ForcePasswordResetResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.ForcePasswordResetResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.adminUserService.ForcePasswordReset(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *adminUserHandler) RestoreUser(ctx context.Context, request *admin.RestoreUserRequest) (*admin.RestoreUserResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.RestoreUserResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.adminUserService.RestoreUser(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *adminUserHandler) Impersonate(ctx context.Context, request *admin.ImpersonateRequest) (*admin.ImpersonateResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.ImpersonateResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.adminUserService.Impersonate(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func NewAdminUserHandler(adminUserService service.IAdminUserService) *adminUserHandler {
+	return &adminUserHandler{
+		adminUserService: adminUserService,
+	}
+}
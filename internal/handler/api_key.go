@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/service"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/admin"
+)
+
+type apiKeyHandler struct {
+	admin.UnimplementedApiKeyServiceServer
+	apiKeyService service.IApiKeyService
+}
+
+func (s *apiKeyHandler) CreateServiceAccount(ctx context.Context, request *admin.CreateServiceAccountRequest) (*admin.CreateServiceAccountResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.CreateServiceAccountResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.apiKeyService.CreateServiceAccount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *apiKeyHandler) CreateApiKey(ctx context.Context, request *admin.CreateApiKeyRequest) (*admin.CreateApiKeyResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.CreateApiKeyResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.apiKeyService.CreateApiKey(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *apiKeyHandler) ListApiKeys(ctx context.Context, request *admin.ListApiKeysRequest) (*admin.ListApiKeysResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.ListApiKeysResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.apiKeyService.ListApiKeys(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *apiKeyHandler) RevokeApiKey(ctx context.Context, request *admin.RevokeApiKeyRequest) (*admin.RevokeApiKeyResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.RevokeApiKeyResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.apiKeyService.RevokeApiKey(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func NewApiKeyHandler(apiKeyService service.IApiKeyService) *apiKeyHandler {
+	return &apiKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
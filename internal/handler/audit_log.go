@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/service"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/admin"
+)
+
+type auditLogHandler struct {
+	admin.UnimplementedAuditLogServiceServer
+	auditLogService service.IAuditLogService
+}
+
+func (s *auditLogHandler) ListAuditLogs(ctx context.Context, request *admin.ListAuditLogsRequest) (*admin.ListAuditLogsResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &admin.ListAuditLogsResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.auditLogService.ListAuditLogs(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func NewAuditLogHandler(auditLogService service.IAuditLogService) *auditLogHandler {
+	return &auditLogHandler{
+		auditLogService: auditLogService,
+	}
+}
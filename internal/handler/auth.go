@@ -90,6 +90,141 @@ func (s *authHandler) GetProfile(ctx context.Context, request *auth.GetProfileRe
 	return res, nil
 }
 
+func (s *authHandler) GetJwks(ctx context.Context, request *auth.GetJwksRequest) (*auth.GetJwksResponse, error) {
+	res, err := s.authService.GetJwks(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *authHandler) UpdateProfile(ctx context.Context, request *auth.UpdateProfileRequest) (*auth.UpdateProfileResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &auth.UpdateProfileResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.authService.UpdateProfile(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *authHandler) ChangeEmail(ctx context.Context, request *auth.ChangeEmailRequest) (*auth.ChangeEmailResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &auth.ChangeEmailResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.authService.ChangeEmail(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *authHandler) ConfirmChangeEmail(ctx context.Context, request *auth.ConfirmChangeEmailRequest) (*auth.ConfirmChangeEmailResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &auth.ConfirmChangeEmailResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.authService.ConfirmChangeEmail(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *authHandler) DeleteAccount(ctx context.Context, request *auth.DeleteAccountRequest) (*auth.DeleteAccountResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &auth.DeleteAccountResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.authService.DeleteAccount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *authHandler) ExportMyData(ctx context.Context, request *auth.ExportMyDataRequest) (*auth.ExportMyDataResponse, error) {
+	res, err := s.authService.ExportMyData(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *authHandler) RequestPasswordReset(ctx context.Context, request *auth.RequestPasswordResetRequest) (*auth.RequestPasswordResetResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &auth.RequestPasswordResetResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.authService.RequestPasswordReset(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *authHandler) ResetPassword(ctx context.Context, request *auth.ResetPasswordRequest) (*auth.ResetPasswordResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &auth.ResetPasswordResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.authService.ResetPassword(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *authHandler) LoginWithOidc(ctx context.Context, request *auth.LoginWithOidcRequest) (*auth.LoginWithOidcResponse, error) {
+	validationErros, err := utils.CheckValidation(request)
+	if err != nil {
+		return nil, err
+	}
+	if validationErros != nil {
+		return &auth.LoginWithOidcResponse{
+			Base: utils.ValidationErrorResponse(validationErros),
+		}, nil
+	}
+	res, err := s.authService.LoginWithOidc(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func NewAuthHandler(authService service.IAuthService) *authHandler {
 	return &authHandler{
 		authService: authService,
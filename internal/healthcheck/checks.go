@@ -0,0 +1,56 @@
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	gocache "github.com/patrickmn/go-cache"
+)
+
+const (
+	CheckPostgres  = "postgres"
+	CheckCache     = "cache"
+	CheckMigration = "migration"
+)
+
+func PostgresCheck(db *sql.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+func CacheCheck(cacheService *gocache.Cache) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		const key = "healthcheck"
+		cacheService.SetDefault(key, true)
+		if _, ok := cacheService.Get(key); !ok {
+			return errors.New("cache is not writable")
+		}
+		return nil
+	}
+}
+
+// MigrationCheck memastikan schema database sudah di versi yang dibutuhkan aplikasi
+// dan tidak sedang dalam status dirty (migration gagal di tengah jalan)
+func MigrationCheck(db *sql.DB, requiredVersion uint) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var version uint
+		var dirty bool
+		err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return errors.New("no migration applied")
+			}
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migration version %d is dirty", version)
+		}
+		if version < requiredVersion {
+			return fmt.Errorf("migration version %d is older than required %d", version, requiredVersion)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+package healthcheck
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// OverallService adalah nama service kosong yang dipakai probe Kubernetes secara default
+const OverallService = ""
+
+type check struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Monitor menjalankan check secara berkala dan memperbarui status grpc.health.v1.
+// Semua service berstatus NOT_SERVING sampai check pertama berhasil,
+// dan kembali NOT_SERVING setelah Shutdown dipanggil.
+type Monitor struct {
+	server   *health.Server
+	interval time.Duration
+	timeout  time.Duration
+
+	mu       sync.Mutex
+	checks   []check
+	services map[string][]string
+	failures map[string]error
+}
+
+func NewMonitor(server *health.Server, interval time.Duration, timeout time.Duration) *Monitor {
+	server.SetServingStatus(OverallService, healthpb.HealthCheckResponse_NOT_SERVING)
+	return &Monitor{
+		server:   server,
+		interval: interval,
+		timeout:  timeout,
+		services: make(map[string][]string),
+		failures: make(map[string]error),
+	}
+}
+
+func (m *Monitor) AddCheck(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks = append(m.checks, check{name: name, fn: fn})
+}
+
+// AddService mendaftarkan service gRPC beserta check yang harus lolos agar SERVING
+func (m *Monitor) AddService(service string, checkNames ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.services[service] = checkNames
+	m.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Run berjalan sampai ctx selesai, dipanggil di goroutine sendiri
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.runChecks(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown mengubah semua status menjadi NOT_SERVING agar load balancer
+// berhenti mengirim request sebelum server dihentikan
+func (m *Monitor) Shutdown() {
+	m.server.Shutdown()
+}
+
+func (m *Monitor) runChecks(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, m.timeout)
+		err := c.fn(checkCtx)
+		cancel()
+		// log hanya ketika status berubah agar log tidak penuh
+		previous, failedBefore := m.failures[c.name]
+		switch {
+		case err != nil && (!failedBefore || previous.Error() != err.Error()):
+			slog.Warn("healthcheck failed", "check", c.name, "error", err)
+		case err == nil && failedBefore:
+			slog.Info("healthcheck recovered", "check", c.name)
+		}
+		if err != nil {
+			m.failures[c.name] = err
+		} else {
+			delete(m.failures, c.name)
+		}
+	}
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	if len(m.failures) > 0 {
+		overall = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	m.server.SetServingStatus(OverallService, overall)
+
+	for service, checkNames := range m.services {
+		status := healthpb.HealthCheckResponse_SERVING
+		for _, name := range checkNames {
+			if _, failed := m.failures[name]; failed {
+				status = healthpb.HealthCheckResponse_NOT_SERVING
+				break
+			}
+		}
+		m.server.SetServingStatus(service, status)
+	}
+}
@@ -0,0 +1,158 @@
+package passwordhash
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// parameter kecil agar test cepat
+var (
+	testArgon2Config = Config{Algorithm: AlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1}
+	testBcryptConfig = Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+)
+
+func newTestHasher(t *testing.T, config Config) IPasswordHasher {
+	t.Helper()
+	hasher, err := NewPasswordHasher(config)
+	if err != nil {
+		t.Fatalf("NewPasswordHasher() error = %v", err)
+	}
+	return hasher
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "argon2id", config: testArgon2Config},
+		{name: "bcrypt", config: testBcryptConfig},
+		{name: "bcrypt cost too low", config: Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost - 1}, wantErr: true},
+		{name: "bcrypt cost too high", config: Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MaxCost + 1}, wantErr: true},
+		{name: "argon2id zero memory", config: Config{Algorithm: AlgorithmArgon2id, Argon2Iterations: 1, Argon2Parallelism: 1}, wantErr: true},
+		{name: "unsupported algorithm", config: Config{Algorithm: "scrypt"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPasswordHasher(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPasswordHasher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHashVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		wantPrefix string
+	}{
+		{name: "argon2id", config: testArgon2Config, wantPrefix: "$argon2id$v=19$m=64,t=1,p=1$"},
+		{name: "bcrypt", config: testBcryptConfig, wantPrefix: "$2a$04$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := newTestHasher(t, tt.config)
+			hash, err := hasher.Hash("Kursi#Jati9")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.wantPrefix) {
+				t.Errorf("Hash() = %q, want prefix %q", hash, tt.wantPrefix)
+			}
+			if ok, err := hasher.Verify(hash, "Kursi#Jati9"); err != nil || !ok {
+				t.Errorf("Verify(correct) = %v, %v, want true, nil", ok, err)
+			}
+			if ok, err := hasher.Verify(hash, "kursi#jati9"); err != nil || ok {
+				t.Errorf("Verify(wrong) = %v, %v, want false, nil", ok, err)
+			}
+			if hasher.NeedsRehash(hash) {
+				t.Error("NeedsRehash() = true for a hash with current parameters")
+			}
+		})
+	}
+}
+
+func TestHashUsesRandomSalt(t *testing.T) {
+	hasher := newTestHasher(t, testArgon2Config)
+	first, err := hasher.Hash("Kursi#Jati9")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	second, err := hasher.Hash("Kursi#Jati9")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if first == second {
+		t.Error("Hash() returned the same hash twice")
+	}
+}
+
+func TestVerifyUnknownFormat(t *testing.T) {
+	hasher := newTestHasher(t, testArgon2Config)
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		// user tanpa password tidak bisa login dengan password
+		{name: "empty hash", hash: "", wantErr: false},
+		{name: "plain text", hash: "Kursi#Jati9", wantErr: true},
+		{name: "unknown prefix", hash: "$scrypt$ln=15$c2FsdA$a2V5", wantErr: true},
+		{name: "truncated argon2id", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", wantErr: true},
+		{name: "unsupported argon2 version", hash: "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := hasher.Verify(tt.hash, "Kursi#Jati9")
+			if ok {
+				t.Error("Verify() = true, want false")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	argon2Hash, err := newTestHasher(t, testArgon2Config).Hash("Kursi#Jati9")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	bcryptHash, err := newTestHasher(t, testBcryptConfig).Hash("Kursi#Jati9")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	stronger := testArgon2Config
+	stronger.Argon2Iterations = 2
+	higherCost := testBcryptConfig
+	higherCost.BcryptCost = bcrypt.MinCost + 1
+
+	tests := []struct {
+		name   string
+		config Config
+		hash   string
+		want   bool
+	}{
+		{name: "argon2id current", config: testArgon2Config, hash: argon2Hash, want: false},
+		{name: "argon2id changed params", config: stronger, hash: argon2Hash, want: true},
+		{name: "bcrypt to argon2id", config: testArgon2Config, hash: bcryptHash, want: true},
+		{name: "bcrypt current", config: testBcryptConfig, hash: bcryptHash, want: false},
+		{name: "bcrypt changed cost", config: higherCost, hash: bcryptHash, want: true},
+		{name: "argon2id to bcrypt", config: testBcryptConfig, hash: argon2Hash, want: true},
+		{name: "malformed argon2id", config: testArgon2Config, hash: "$argon2id$broken", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTestHasher(t, tt.config).NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/data"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/common"
+)
+
+type Policy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// tolak password yang mengandung bagian dari email atau nama user
+	DisallowPersonalInfo bool
+	commonPasswords      map[string]struct{}
+}
+
+// minimal panjang bagian email/nama yang dicek, agar "a" atau "li" tidak ikut ditolak
+const minPersonalInfoLength = 3
+
+// LoadCommonPasswords membaca daftar password umum, satu password per baris.
+// Baris kosong dan baris yang diawali # diabaikan. Path kosong memakai daftar bawaan yang di-embed.
+func (p *Policy) LoadCommonPasswords(path string) error {
+	if path == "" {
+		return p.ReadCommonPasswords(bytes.NewReader(data.CommonPasswords))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return p.ReadCommonPasswords(file)
+}
+
+func (p *Policy) ReadCommonPasswords(r io.Reader) error {
+	commonPasswords := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commonPasswords[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	p.commonPasswords = commonPasswords
+	return nil
+}
+
+// Validate mengembalikan setiap aturan yang dilanggar sebagai ValidationError untuk field
+func (p *Policy) Validate(field string, password string, email string, fullName string) []*common.ValidationError {
+	violations := make([]*common.ValidationError, 0)
+	addViolation := func(message string) {
+		violations = append(violations, &common.ValidationError{
+			Field:   field,
+			Message: message,
+		})
+	}
+
+	if len([]rune(password)) < p.MinLength {
+		addViolation(fmt.Sprintf("password must be at least %d characters", p.MinLength))
+	}
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		addViolation("password must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		addViolation("password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		addViolation("password must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		addViolation("password must contain a symbol")
+	}
+
+	lowerPassword := strings.ToLower(password)
+	if p.DisallowPersonalInfo {
+		for _, part := range personalInfoParts(email, fullName) {
+			if strings.Contains(lowerPassword, part) {
+				addViolation("password must not contain your email or name")
+				break
+			}
+		}
+	}
+	if _, ok := p.commonPasswords[lowerPassword]; ok {
+		addViolation("password is too common")
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return violations
+}
+
+func personalInfoParts(email string, fullName string) []string {
+	parts := make([]string, 0)
+	candidates := strings.Fields(strings.ToLower(fullName))
+	if localPart, _, ok := strings.Cut(strings.ToLower(email), "@"); ok {
+		candidates = append(candidates, localPart)
+		candidates = append(candidates, strings.FieldsFunc(localPart, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	for _, candidate := range candidates {
+		if len([]rune(candidate)) >= minPersonalInfoLength {
+			parts = append(parts, candidate)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,86 @@
+package passwordpolicy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadCommonPasswordsEmbedded(t *testing.T) {
+	policy := &Policy{}
+	if err := policy.LoadCommonPasswords(""); err != nil {
+		t.Fatalf("LoadCommonPasswords() error = %v", err)
+	}
+	if len(policy.commonPasswords) == 0 {
+		t.Fatal("embedded common password list is empty")
+	}
+	if _, ok := policy.commonPasswords["123456"]; !ok {
+		t.Error("embedded list does not contain 123456")
+	}
+	for password := range policy.commonPasswords {
+		if strings.HasPrefix(password, "#") {
+			t.Errorf("comment line %q loaded as password", password)
+		}
+	}
+}
+
+func TestLoadCommonPasswordsMissingFile(t *testing.T) {
+	policy := &Policy{}
+	if err := policy.LoadCommonPasswords("does-not-exist.txt"); err == nil {
+		t.Error("LoadCommonPasswords() error = nil, want error for missing file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	policy := &Policy{
+		MinLength:            8,
+		RequireUpper:         true,
+		RequireLower:         true,
+		RequireDigit:         true,
+		RequireSymbol:        true,
+		DisallowPersonalInfo: true,
+	}
+	if err := policy.ReadCommonPasswords(strings.NewReader("# komentar\n\nPassw0rd!\n")); err != nil {
+		t.Fatalf("ReadCommonPasswords() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		fullName string
+		want     []string
+	}{
+		{name: "valid", password: "Kursi#Jati9", email: "budi@example.com", fullName: "Budi Santoso"},
+		{name: "too short", password: "Ab1!", want: []string{"password must be at least 8 characters"}},
+		{name: "missing upper", password: "kursi#jati9", want: []string{"password must contain an uppercase letter"}},
+		{name: "missing lower", password: "KURSI#JATI9", want: []string{"password must contain a lowercase letter"}},
+		{name: "missing digit", password: "Kursi#Jati", want: []string{"password must contain a digit"}},
+		{name: "missing symbol", password: "KursiJati9", want: []string{"password must contain a symbol"}},
+		{name: "common password case insensitive", password: "pASSW0RD!", want: []string{"password is too common"}},
+		{name: "contains name", password: "Santoso#99", fullName: "Budi Santoso", want: []string{"password must not contain your email or name"}},
+		{name: "contains email local part", password: "Xbudi.k#99", email: "budi.k@example.com", want: []string{"password must not contain your email or name"}},
+		// bagian nama yang terlalu pendek tidak dicek
+		{name: "short name part ignored", password: "Kursi#Li99", fullName: "Li"},
+		{name: "multiple violations", password: "abc", want: []string{
+			"password must be at least 8 characters",
+			"password must contain an uppercase letter",
+			"password must contain a digit",
+			"password must contain a symbol",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := policy.Validate("password", tt.password, tt.email, tt.fullName)
+			got := make([]string, 0, len(violations))
+			for _, violation := range violations {
+				if violation.Field != "password" {
+					t.Errorf("violation field = %q, want password", violation.Field)
+				}
+				got = append(got, violation.Message)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+)
+
+type IAddressRepository interface {
+	CountAddressesByUserId(ctx context.Context, userId string) (int64, error)
+	ListAddressesByUserId(ctx context.Context, userId string) ([]*entity.Address, error)
+	GetAddressById(ctx context.Context, userId string, addressId string) (*entity.Address, error)
+	InsertAddress(ctx context.Context, address *entity.Address) error
+	UpdateAddress(ctx context.Context, address *entity.Address) error
+	SoftDeleteAddress(ctx context.Context, userId string, addressId string, deletedBy string) error
+	UnsetDefaultAddresses(ctx context.Context, userId string, shipping bool, billing bool, updatedBy string) error
+	PromoteDefaultAddress(ctx context.Context, userId string, shipping bool, billing bool, updatedBy string) error
+}
+
+const addressColumns = "id, user_id, label, recipient_name, phone_number, street_address, province, city, district, sub_district, postal_code, notes, latitude, longitude, is_default_shipping, is_default_billing, created_at, updated_at"
+
+type addressRepository struct {
+	db *sql.DB
+}
+
+func (s *addressRepository) CountAddressesByUserId(ctx context.Context, userId string) (int64, error) {
+	var total int64
+	err := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM address WHERE user_id = $1 AND is_deleted IS false", userId).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *addressRepository) ListAddressesByUserId(ctx context.Context, userId string) ([]*entity.Address, error) {
+	rows, err := database.Executor(ctx, s.db).QueryContext(ctx, "SELECT "+addressColumns+" FROM address WHERE user_id = $1 AND is_deleted IS false ORDER BY is_default_shipping DESC, created_at ASC", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := make([]*entity.Address, 0)
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// GetAddressById selalu dibatasi user_id agar user tidak bisa membaca alamat milik user lain
+func (s *addressRepository) GetAddressById(ctx context.Context, userId string, addressId string) (*entity.Address, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT "+addressColumns+" FROM address WHERE id = $1 AND user_id = $2 AND is_deleted IS false", addressId, userId)
+	address, err := scanAddress(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return address, nil
+}
+
+func (s *addressRepository) InsertAddress(ctx context.Context, address *entity.Address) error {
+	latitude, longitude := nullGeoPoint(address.Location)
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "INSERT INTO address (id, user_id, label, recipient_name, phone_number, street_address, province, city, district, sub_district, postal_code, notes, latitude, longitude, is_default_shipping, is_default_billing, created_at, created_by, is_deleted) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, false)",
+		address.Id,
+		address.UserId,
+		address.Label,
+		address.RecipientName,
+		address.PhoneNumber,
+		address.StreetAddress,
+		address.Province,
+		address.City,
+		address.District,
+		address.SubDistrict,
+		address.PostalCode,
+		nullString(address.Notes),
+		latitude,
+		longitude,
+		address.IsDefaultShipping,
+		address.IsDefaultBilling,
+		address.CreatedAt,
+		address.CreatedBy,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *addressRepository) UpdateAddress(ctx context.Context, address *entity.Address) error {
+	latitude, longitude := nullGeoPoint(address.Location)
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE address SET label = $1, recipient_name = $2, phone_number = $3, street_address = $4, province = $5, city = $6, district = $7, sub_district = $8, postal_code = $9, notes = $10, latitude = $11, longitude = $12, is_default_shipping = $13, is_default_billing = $14, updated_at = $15, updated_by = $16 WHERE id = $17 AND user_id = $18 AND is_deleted IS false",
+		address.Label,
+		address.RecipientName,
+		address.PhoneNumber,
+		address.StreetAddress,
+		address.Province,
+		address.City,
+		address.District,
+		address.SubDistrict,
+		address.PostalCode,
+		nullString(address.Notes),
+		latitude,
+		longitude,
+		address.IsDefaultShipping,
+		address.IsDefaultBilling,
+		time.Now(),
+		address.UpdatedBy,
+		address.Id,
+		address.UserId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *addressRepository) SoftDeleteAddress(ctx context.Context, userId string, addressId string, deletedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE address SET is_deleted = true, is_default_shipping = false, is_default_billing = false, deleted_at = $1, deleted_by = $2 WHERE id = $3 AND user_id = $4",
+		time.Now(),
+		deletedBy,
+		addressId,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnsetDefaultAddresses melepas flag default sebelum alamat lain dijadikan default,
+// hanya boleh ada satu alamat default pengiriman dan satu alamat default penagihan per user
+func (s *addressRepository) UnsetDefaultAddresses(ctx context.Context, userId string, shipping bool, billing bool, updatedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, `UPDATE address SET
+			is_default_shipping = is_default_shipping AND NOT $1,
+			is_default_billing = is_default_billing AND NOT $2,
+			updated_at = $3,
+			updated_by = $4
+		WHERE user_id = $5 AND is_deleted IS false AND ((is_default_shipping AND $1) OR (is_default_billing AND $2))`,
+		shipping,
+		billing,
+		time.Now(),
+		updatedBy,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// PromoteDefaultAddress menjadikan alamat terbaru user sebagai default pengiriman
+// dan/atau penagihan, dipakai setelah alamat default dihapus. Tidak melakukan
+// apa pun jika user tidak punya alamat lain.
+func (s *addressRepository) PromoteDefaultAddress(ctx context.Context, userId string, shipping bool, billing bool, updatedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, `UPDATE address SET
+			is_default_shipping = is_default_shipping OR $1,
+			is_default_billing = is_default_billing OR $2,
+			updated_at = $3,
+			updated_by = $4
+		WHERE id = (
+			SELECT id FROM address WHERE user_id = $5 AND is_deleted IS false ORDER BY created_at DESC LIMIT 1
+		)`,
+		shipping,
+		billing,
+		time.Now(),
+		updatedBy,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func scanAddress(row scanner) (*entity.Address, error) {
+	var address entity.Address
+	var notes sql.NullString
+	var latitude, longitude sql.NullFloat64
+	var updatedAt sql.NullTime
+	err := row.Scan(
+		&address.Id,
+		&address.UserId,
+		&address.Label,
+		&address.RecipientName,
+		&address.PhoneNumber,
+		&address.StreetAddress,
+		&address.Province,
+		&address.City,
+		&address.District,
+		&address.SubDistrict,
+		&address.PostalCode,
+		&notes,
+		&latitude,
+		&longitude,
+		&address.IsDefaultShipping,
+		&address.IsDefaultBilling,
+		&address.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	address.Notes = notes.String
+	address.UpdatedAt = updatedAt.Time
+	if latitude.Valid && longitude.Valid {
+		address.Location = &entity.GeoPoint{
+			Latitude:  latitude.Float64,
+			Longitude: longitude.Float64,
+		}
+	}
+	return &address, nil
+}
+
+func nullGeoPoint(location *entity.GeoPoint) (sql.NullFloat64, sql.NullFloat64) {
+	if location == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: location.Latitude, Valid: true}, sql.NullFloat64{Float64: location.Longitude, Valid: true}
+}
+
+func NewAddressRepository(db *sql.DB) IAddressRepository {
+	return &addressRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+)
+
+type IAdminUserRepository interface {
+	ListUsers(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int64, error)
+	GetUserById(ctx context.Context, userId string) (*entity.User, error)
+	UpdateUserRole(ctx context.Context, userId string, roleCode string, updatedBy string) error
+	UpdateUserDisabled(ctx context.Context, userId string, isDisabled bool, updatedBy string) error
+	UpdateUserPasswordResetRequired(ctx context.Context, userId string, updatedBy string) error
+	RestoreUser(ctx context.Context, userId string, updatedBy string) error
+}
+
+type adminUserRepository struct {
+	db *sql.DB
+}
+
+func (s *adminUserRepository) ListUsers(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int64, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	switch filter.DeletedStatus {
+	case entity.UserDeletedStatusDeleted:
+		conditions = append(conditions, "is_deleted IS true")
+	case entity.UserDeletedStatusAll:
+	default:
+		conditions = append(conditions, "is_deleted IS false")
+	}
+	if filter.RoleCode != "" {
+		addCondition("role_code = $%d", filter.RoleCode)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		addCondition("created_at >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		addCondition("created_at <= $%d", filter.CreatedTo)
+	}
+	if filter.Search != "" {
+		addCondition("(full_name ILIKE $%[1]d OR email ILIKE $%[1]d)", "%"+escapeLike(filter.Search)+"%")
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	err := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM \"user\""+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	rows, err := database.Executor(ctx, s.db).QueryContext(ctx, fmt.Sprintf("SELECT "+userColumns+" FROM \"user\"%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d", where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := make([]*entity.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// GetUserById juga mengembalikan user yang sudah dihapus
+func (s *adminUserRepository) GetUserById(ctx context.Context, userId string) (*entity.User, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT "+userColumns+" FROM \"user\" WHERE id = $1", userId)
+	return scanUser(row)
+}
+
+func (s *adminUserRepository) UpdateUserRole(ctx context.Context, userId string, roleCode string, updatedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET role_code = $1, updated_at = $2, updated_by = $3 WHERE id = $4",
+		roleCode,
+		time.Now(),
+		updatedBy,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *adminUserRepository) UpdateUserDisabled(ctx context.Context, userId string, isDisabled bool, updatedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET is_disabled = $1, updated_at = $2, updated_by = $3 WHERE id = $4",
+		isDisabled,
+		time.Now(),
+		updatedBy,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *adminUserRepository) UpdateUserPasswordResetRequired(ctx context.Context, userId string, updatedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET password_reset_required = true, updated_at = $1, updated_by = $2 WHERE id = $3",
+		time.Now(),
+		updatedBy,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *adminUserRepository) RestoreUser(ctx context.Context, userId string, updatedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET is_deleted = false, deleted_at = NULL, deleted_by = NULL, updated_at = $1, updated_by = $2 WHERE id = $3 AND anonymized_at IS NULL",
+		time.Now(),
+		updatedBy,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func NewAdminUserRepository(db *sql.DB) IAdminUserRepository {
+	return &adminUserRepository{
+		db: db,
+	}
+}
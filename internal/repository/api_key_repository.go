@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+)
+
+type IApiKeyRepository interface {
+	InsertApiKey(ctx context.Context, apiKey *entity.ApiKey) error
+	GetApiKeyById(ctx context.Context, apiKeyId string) (*entity.ApiKey, error)
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (*entity.ApiKey, error)
+	ListApiKeys(ctx context.Context, filter entity.ApiKeyFilter) ([]*entity.ApiKey, int64, error)
+	RevokeApiKey(ctx context.Context, apiKeyId string) error
+	UpdateApiKeyLastUsed(ctx context.Context, apiKeyId string, lastUsedAt time.Time) error
+}
+
+const apiKeyColumns = "id, user_id, name, prefix, secret_hash, scopes, expires_at, last_used_at, revoked_at, created_at, created_by"
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func (s *apiKeyRepository) InsertApiKey(ctx context.Context, apiKey *entity.ApiKey) error {
+	var expiresAt sql.NullTime
+	if !apiKey.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: apiKey.ExpiresAt, Valid: true}
+	}
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "INSERT INTO api_key (id, user_id, name, prefix, secret_hash, scopes, expires_at, created_at, created_by) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		apiKey.Id,
+		apiKey.UserId,
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.SecretHash,
+		pq.Array(apiKey.Scopes),
+		expiresAt,
+		apiKey.CreatedAt,
+		apiKey.CreatedBy,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *apiKeyRepository) GetApiKeyById(ctx context.Context, apiKeyId string) (*entity.ApiKey, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_key WHERE id = $1", apiKeyId)
+	return scanApiKey(row)
+}
+
+func (s *apiKeyRepository) GetApiKeyByPrefix(ctx context.Context, prefix string) (*entity.ApiKey, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_key WHERE prefix = $1", prefix)
+	return scanApiKey(row)
+}
+
+func (s *apiKeyRepository) ListApiKeys(ctx context.Context, filter entity.ApiKeyFilter) ([]*entity.ApiKey, int64, error) {
+	where := ""
+	args := make([]any, 0)
+	if filter.UserId != "" {
+		where = " WHERE user_id = $1"
+		args = append(args, filter.UserId)
+	}
+
+	var total int64
+	err := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM api_key"+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	limit := "$1 OFFSET $2"
+	if filter.UserId != "" {
+		limit = "$2 OFFSET $3"
+	}
+	rows, err := database.Executor(ctx, s.db).QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_key"+where+" ORDER BY created_at DESC LIMIT "+limit, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	apiKeys := make([]*entity.ApiKey, 0)
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return apiKeys, total, nil
+}
+
+func (s *apiKeyRepository) RevokeApiKey(ctx context.Context, apiKeyId string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE api_key SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL",
+		time.Now(),
+		apiKeyId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *apiKeyRepository) UpdateApiKeyLastUsed(ctx context.Context, apiKeyId string, lastUsedAt time.Time) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE api_key SET last_used_at = $1 WHERE id = $2",
+		lastUsedAt,
+		apiKeyId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func scanApiKey(row scanner) (*entity.ApiKey, error) {
+	var apiKey entity.ApiKey
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&apiKey.Id,
+		&apiKey.UserId,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.SecretHash,
+		pq.Array(&apiKey.Scopes),
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&apiKey.CreatedAt,
+		&apiKey.CreatedBy,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	apiKey.ExpiresAt = expiresAt.Time
+	apiKey.LastUsedAt = lastUsedAt.Time
+	apiKey.RevokedAt = revokedAt.Time
+	return &apiKey, nil
+}
+
+func NewApiKeyRepository(db *sql.DB) IApiKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+)
+
+// audit log hanya boleh ditambah, tidak ada update maupun delete
+type IAuditLogRepository interface {
+	InsertAuditLog(ctx context.Context, auditLog *entity.AuditLog) error
+	ListAuditLogs(ctx context.Context, filter entity.AuditLogFilter) ([]*entity.AuditLog, int64, error)
+}
+
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+func (s *auditLogRepository) InsertAuditLog(ctx context.Context, auditLog *entity.AuditLog) error {
+	changes, err := json.Marshal(auditLog.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = database.Executor(ctx, s.db).ExecContext(ctx, "INSERT INTO audit_log (id, actor_id, action, target_type, target_id, changes, request_id, ip_address, method, created_at, impersonator_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		auditLog.Id,
+		nullString(auditLog.ActorId),
+		auditLog.Action,
+		auditLog.TargetType,
+		auditLog.TargetId,
+		changes,
+		nullString(auditLog.RequestId),
+		nullString(auditLog.IpAddress),
+		nullString(auditLog.Method),
+		auditLog.CreatedAt,
+		nullString(auditLog.ImpersonatorId),
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *auditLogRepository) ListAuditLogs(ctx context.Context, filter entity.AuditLogFilter) ([]*entity.AuditLog, int64, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorId != "" {
+		addCondition("actor_id = $%d", filter.ActorId)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.TargetType != "" {
+		addCondition("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetId != "" {
+		addCondition("target_id = $%d", filter.TargetId)
+	}
+	if filter.ImpersonatorId != "" {
+		addCondition("impersonator_id = $%d", filter.ImpersonatorId)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		addCondition("created_at >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		addCondition("created_at <= $%d", filter.CreatedTo)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	err := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	rows, err := database.Executor(ctx, s.db).QueryContext(ctx, fmt.Sprintf("SELECT id, actor_id, action, target_type, target_id, changes, request_id, ip_address, method, created_at, impersonator_id FROM audit_log%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d", where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	auditLogs := make([]*entity.AuditLog, 0)
+	for rows.Next() {
+		var auditLog entity.AuditLog
+		var actorId, requestId, ipAddress, method, impersonatorId sql.NullString
+		var changes []byte
+		err := rows.Scan(
+			&auditLog.Id,
+			&actorId,
+			&auditLog.Action,
+			&auditLog.TargetType,
+			&auditLog.TargetId,
+			&changes,
+			&requestId,
+			&ipAddress,
+			&method,
+			&auditLog.CreatedAt,
+			&impersonatorId,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		auditLog.ActorId = actorId.String
+		auditLog.RequestId = requestId.String
+		auditLog.IpAddress = ipAddress.String
+		auditLog.Method = method.String
+		auditLog.ImpersonatorId = impersonatorId.String
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &auditLog.Changes); err != nil {
+				return nil, 0, err
+			}
+		}
+		auditLogs = append(auditLogs, &auditLog)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return auditLogs, total, nil
+}
+
+func NewAuditLogRepository(db *sql.DB) IAuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
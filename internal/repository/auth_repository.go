@@ -3,16 +3,39 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
 )
 
 type IAuthRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
 	InsertUser(ctx context.Context, user *entity.User) error
 	UpdateUserPassword(ctx context.Context, userId string, hashedNewPassword string, updatedBy string) error
+	UpdateUserPasswordHash(ctx context.Context, userId string, hashedPassword string) error
+	GetUserById(ctx context.Context, userId string) (*entity.User, error)
+	UpdateUserProfile(ctx context.Context, user *entity.User) error
+	UpdateUserEmail(ctx context.Context, userId string, email string, updatedBy string) error
+	SoftDeleteUser(ctx context.Context, userId string, deletedBy string) error
+	RevokeUserTokens(ctx context.Context, userId string, validAfter time.Time) error
+	AnonymizeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error)
+	GetUserIdentity(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error)
+	InsertUserIdentity(ctx context.Context, identity *entity.UserIdentity) error
+	RelinkUserIdentity(ctx context.Context, identityId string, userId string, email string) error
+	InsertPendingEmailChange(ctx context.Context, change *entity.PendingEmailChange) error
+	ConsumePendingEmailChange(ctx context.Context, tokenHash string) (*entity.PendingEmailChange, error)
+	InsertPasswordResetToken(ctx context.Context, token *entity.PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+}
+
+const userColumns = "id, email, password, full_name, role_code, phone_number, avatar_url, preferences, is_disabled, password_reset_required, tokens_valid_after, created_at, updated_at, is_deleted, deleted_at"
+
+type scanner interface {
+	Scan(dest ...any) error
 }
 
 type authRepository struct {
@@ -20,30 +43,30 @@ type authRepository struct {
 }
 
 func (s *authRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
-	row := s.db.QueryRowContext(ctx, "SELECT id,email, password, full_name, role_code, created_at FROM \"user\" WHERE email = $1 AND is_deleted IS false", email)
-	if row.Err() != nil {
-		return nil, row.Err()
-	}
-	var user entity.User
-	err := row.Scan(
-		&user.Id,
-		&user.Email,
-		&user.Password,
-		&user.FullName,
-		&user.RoleCode,
-		&user.CreatedAt,
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT "+userColumns+" FROM \"user\" WHERE email = $1 AND is_deleted IS false", email)
+	return scanUser(row)
+}
+
+// UpdateUserPasswordHash hanya mengganti format hash dari password yang sama,
+// sehingga updated_at dan updated_by tidak diubah
+func (s *authRepository) UpdateUserPasswordHash(ctx context.Context, userId string, hashedPassword string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET password = $1 WHERE id = $2",
+		hashedPassword,
+		userId,
 	)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
+		return err
 	}
-	return &user, nil
+	return nil
+}
+
+func (s *authRepository) GetUserById(ctx context.Context, userId string) (*entity.User, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT "+userColumns+" FROM \"user\" WHERE id = $1 AND is_deleted IS false", userId)
+	return scanUser(row)
 }
 
 func (s *authRepository) InsertUser(ctx context.Context, user *entity.User) error {
-	_, err := s.db.ExecContext(ctx, "INSERT INTO \"user\" (id, full_name,email, password,role_code, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by, is_deleted) VALUES ($1, $2, $3, $4, $5, $6, $7,$8,$9,$10,$11,$12)",
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "INSERT INTO \"user\" (id, full_name,email, password,role_code, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by, is_deleted) VALUES ($1, $2, $3, $4, $5, $6, $7,$8,$9,$10,$11,$12)",
 		user.Id,
 		user.FullName,
 		user.Email,
@@ -63,7 +86,7 @@ func (s *authRepository) InsertUser(ctx context.Context, user *entity.User) erro
 	return nil
 }
 func (s *authRepository) UpdateUserPassword(ctx context.Context, userId string, hashedNewPassword string, updatedBy string) error {
-	_, err := s.db.ExecContext(ctx, "UPDATE \"user\" SET password = $1, updated_at = $2, updated_by = $3 WHERE id = $4",
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET password = $1, password_reset_required = false, updated_at = $2, updated_by = $3 WHERE id = $4",
 		hashedNewPassword,
 		time.Now(),
 		updatedBy,
@@ -75,6 +98,285 @@ func (s *authRepository) UpdateUserPassword(ctx context.Context, userId string,
 	return nil
 }
 
+func (s *authRepository) UpdateUserProfile(ctx context.Context, user *entity.User) error {
+	preferences, err := json.Marshal(user.Preferences)
+	if err != nil {
+		return err
+	}
+	_, err = database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET full_name = $1, phone_number = $2, avatar_url = $3, preferences = $4, updated_at = $5, updated_by = $6 WHERE id = $7",
+		user.FullName,
+		nullString(user.PhoneNumber),
+		nullString(user.AvatarUrl),
+		preferences,
+		user.UpdatedAt,
+		user.UpdatedBy,
+		user.Id,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *authRepository) UpdateUserEmail(ctx context.Context, userId string, email string, updatedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET email = $1, updated_at = $2, updated_by = $3 WHERE id = $4",
+		email,
+		time.Now(),
+		updatedBy,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *authRepository) SoftDeleteUser(ctx context.Context, userId string, deletedBy string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET is_deleted = true, deleted_at = $1, deleted_by = $2 WHERE id = $3 AND is_deleted IS false",
+		time.Now(),
+		deletedBy,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevokeUserTokens menolak semua token user yang terbit sebelum validAfter
+func (s *authRepository) RevokeUserTokens(ctx context.Context, userId string, validAfter time.Time) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE \"user\" SET tokens_valid_after = $1 WHERE id = $2",
+		validAfter,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// AnonymizeDeletedUsers menghapus data pribadi user yang sudah dihapus sebelum deletedBefore.
+// Email diganti agar unik dan tidak bisa dipakai login lagi, alamat, identity
+// OIDC, permintaan ganti email dan token reset password user ikut dihapus.
+// Kolom created_by dan updated_by dikosongkan karena data lama bisa berisi nama user itu sendiri.
+func (s *authRepository) AnonymizeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var total int64
+	err := database.Executor(ctx, s.db).QueryRowContext(ctx, `WITH anonymized AS (
+			UPDATE "user" SET
+				full_name = 'Deleted User',
+				email = 'deleted-' || id || '@anonymized.invalid',
+				password = '',
+				phone_number = NULL,
+				avatar_url = NULL,
+				preferences = '{}',
+				created_by = NULL,
+				updated_by = NULL,
+				anonymized_at = $1
+			WHERE is_deleted IS true AND anonymized_at IS NULL AND deleted_at < $2
+			RETURNING id
+		), deleted_address AS (
+			DELETE FROM address WHERE user_id IN (SELECT id FROM anonymized)
+		), anonymized_api_key AS (
+			UPDATE api_key SET created_by = NULL WHERE user_id IN (SELECT id FROM anonymized)
+		), deleted_identity AS (
+			DELETE FROM user_identity WHERE user_id IN (SELECT id FROM anonymized)
+		), deleted_email_change AS (
+			DELETE FROM pending_email_change WHERE user_id IN (SELECT id FROM anonymized)
+		), deleted_password_reset AS (
+			DELETE FROM password_reset_token WHERE user_id IN (SELECT id FROM anonymized)
+		)
+		SELECT COUNT(*) FROM anonymized`,
+		time.Now(),
+		deletedBefore,
+	).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *authRepository) GetUserIdentity(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT id, user_id, provider, subject, email, created_at FROM user_identity WHERE provider = $1 AND subject = $2", provider, subject)
+	var identity entity.UserIdentity
+	err := row.Scan(
+		&identity.Id,
+		&identity.UserId,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (s *authRepository) InsertUserIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "INSERT INTO user_identity (id, user_id, provider, subject, email, created_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		identity.Id,
+		identity.UserId,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RelinkUserIdentity memindahkan identity ke user lain, dipakai saat user lama
+// sudah dihapus dan pemilik akun provider login lagi
+func (s *authRepository) RelinkUserIdentity(ctx context.Context, identityId string, userId string, email string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE user_identity SET user_id = $1, email = $2 WHERE id = $3",
+		userId,
+		email,
+		identityId,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// InsertPendingEmailChange menyimpan permintaan ganti email baru dan membatalkan
+// permintaan user yang belum dikonfirmasi
+func (s *authRepository) InsertPendingEmailChange(ctx context.Context, change *entity.PendingEmailChange) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, `WITH cancelled AS (
+			DELETE FROM pending_email_change WHERE user_id = $2
+		)
+		INSERT INTO pending_email_change (token_hash, user_id, new_email, expires_at, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		change.TokenHash,
+		change.UserId,
+		change.NewEmail,
+		change.ExpiresAt,
+		change.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConsumePendingEmailChange menghapus dan mengembalikan permintaan ganti email,
+// sehingga token hanya bisa dipakai sekali. Token yang kedaluwarsa juga ikut
+// terhapus, pemanggil wajib mengecek ExpiresAt.
+func (s *authRepository) ConsumePendingEmailChange(ctx context.Context, tokenHash string) (*entity.PendingEmailChange, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "DELETE FROM pending_email_change WHERE token_hash = $1 RETURNING token_hash, user_id, new_email, expires_at, created_at", tokenHash)
+	var change entity.PendingEmailChange
+	err := row.Scan(
+		&change.TokenHash,
+		&change.UserId,
+		&change.NewEmail,
+		&change.ExpiresAt,
+		&change.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+// InsertPasswordResetToken menyimpan token reset password baru dan membatalkan
+// token user yang belum dipakai
+func (s *authRepository) InsertPasswordResetToken(ctx context.Context, token *entity.PasswordResetToken) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, `WITH cancelled AS (
+			DELETE FROM password_reset_token WHERE user_id = $2
+		)
+		INSERT INTO password_reset_token (token_hash, user_id, expires_at, created_at) VALUES ($1, $2, $3, $4)`,
+		token.TokenHash,
+		token.UserId,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *authRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT token_hash, user_id, expires_at, created_at FROM password_reset_token WHERE token_hash = $1", tokenHash)
+	return scanPasswordResetToken(row)
+}
+
+// ConsumePasswordResetToken menghapus dan mengembalikan token reset password,
+// sehingga token hanya bisa dipakai sekali. Pemanggil wajib mengecek ExpiresAt.
+func (s *authRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	row := database.Executor(ctx, s.db).QueryRowContext(ctx, "DELETE FROM password_reset_token WHERE token_hash = $1 RETURNING token_hash, user_id, expires_at, created_at", tokenHash)
+	return scanPasswordResetToken(row)
+}
+
+func scanPasswordResetToken(row scanner) (*entity.PasswordResetToken, error) {
+	var token entity.PasswordResetToken
+	err := row.Scan(
+		&token.TokenHash,
+		&token.UserId,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func scanUser(row scanner) (*entity.User, error) {
+	var user entity.User
+	var phoneNumber, avatarUrl sql.NullString
+	var tokensValidAfter, updatedAt, deletedAt sql.NullTime
+	var preferences []byte
+	err := row.Scan(
+		&user.Id,
+		&user.Email,
+		&user.Password,
+		&user.FullName,
+		&user.RoleCode,
+		&phoneNumber,
+		&avatarUrl,
+		&preferences,
+		&user.IsDisabled,
+		&user.PasswordResetRequired,
+		&tokensValidAfter,
+		&user.CreatedAt,
+		&updatedAt,
+		&user.IsDeleted,
+		&deletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	user.PhoneNumber = phoneNumber.String
+	user.AvatarUrl = avatarUrl.String
+	user.TokensValidAfter = tokensValidAfter.Time
+	user.UpdatedAt = updatedAt.Time
+	user.DeletedAt = deletedAt.Time
+	if len(preferences) > 0 {
+		if err := json.Unmarshal(preferences, &user.Preferences); err != nil {
+			return nil, err
+		}
+	}
+	return &user, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
 func NewAuthRepository(db *sql.DB) IAuthRepository {
 	return &authRepository{
 		db: db,
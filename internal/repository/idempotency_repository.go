@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+)
+
+type IIdempotencyRepository interface {
+	// ReserveIdempotencyKey mengembalikan false jika key sudah dipakai dan belum kedaluwarsa
+	ReserveIdempotencyKey(ctx context.Context, idempotencyKey *entity.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(ctx context.Context, scope string, method string, key string) (*entity.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, scope string, method string, key string, response []byte, expiresAt time.Time) error
+	DeleteIdempotencyKey(ctx context.Context, scope string, method string, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func (s *idempotencyRepository) ReserveIdempotencyKey(ctx context.Context, idempotencyKey *entity.IdempotencyKey) (bool, error) {
+	// baris yang sudah kedaluwarsa (termasuk request yang tidak pernah selesai) boleh ditimpa
+	result, err := database.Executor(ctx, s.db).ExecContext(ctx, `INSERT INTO idempotency_key (scope, method, key, request_hash, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (scope, method, key) DO UPDATE SET request_hash = EXCLUDED.request_hash, response = NULL, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_key.expires_at <= EXCLUDED.created_at`,
+		idempotencyKey.Scope,
+		idempotencyKey.Method,
+		idempotencyKey.Key,
+		idempotencyKey.RequestHash,
+		idempotencyKey.CreatedAt,
+		idempotencyKey.ExpiresAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (s *idempotencyRepository) GetIdempotencyKey(ctx context.Context, scope string, method string, key string) (*entity.IdempotencyKey, error) {
+	var idempotencyKey entity.IdempotencyKey
+	err := database.Executor(ctx, s.db).QueryRowContext(ctx, "SELECT scope, method, key, request_hash, response, created_at, expires_at FROM idempotency_key WHERE scope = $1 AND method = $2 AND key = $3",
+		scope,
+		method,
+		key,
+	).Scan(
+		&idempotencyKey.Scope,
+		&idempotencyKey.Method,
+		&idempotencyKey.Key,
+		&idempotencyKey.RequestHash,
+		&idempotencyKey.Response,
+		&idempotencyKey.CreatedAt,
+		&idempotencyKey.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &idempotencyKey, nil
+}
+
+func (s *idempotencyRepository) CompleteIdempotencyKey(ctx context.Context, scope string, method string, key string, response []byte, expiresAt time.Time) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "UPDATE idempotency_key SET response = $1, expires_at = $2 WHERE scope = $3 AND method = $4 AND key = $5",
+		response,
+		expiresAt,
+		scope,
+		method,
+		key,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *idempotencyRepository) DeleteIdempotencyKey(ctx context.Context, scope string, method string, key string) error {
+	_, err := database.Executor(ctx, s.db).ExecContext(ctx, "DELETE FROM idempotency_key WHERE scope = $1 AND method = $2 AND key = $3",
+		scope,
+		method,
+		key,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *idempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	result, err := database.Executor(ctx, s.db).ExecContext(ctx, "DELETE FROM idempotency_key WHERE expires_at <= $1", now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func NewIdempotencyRepository(db *sql.DB) IIdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/address"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type IAddressService interface {
+	CreateAddress(ctx context.Context, request *address.CreateAddressRequest) (*address.CreateAddressResponse, error)
+	ListAddresses(ctx context.Context, request *address.ListAddressesRequest) (*address.ListAddressesResponse, error)
+	GetAddress(ctx context.Context, request *address.GetAddressRequest) (*address.GetAddressResponse, error)
+	UpdateAddress(ctx context.Context, request *address.UpdateAddressRequest) (*address.UpdateAddressResponse, error)
+	DeleteAddress(ctx context.Context, request *address.DeleteAddressRequest) (*address.DeleteAddressResponse, error)
+}
+
+type addressService struct {
+	txManager         database.ITransactionManager
+	addressRepository repository.IAddressRepository
+}
+
+func (s *addressService) CreateAddress(ctx context.Context, request *address.CreateAddressRequest) (*address.CreateAddressResponse, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.CreateAddress")
+	defer span.End()
+
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *address.CreateAddressResponse
+	// serializable agar batas jumlah alamat dan alamat default tetap benar saat request bersamaan
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		total, err := s.addressRepository.CountAddressesByUserId(ctx, claims.Subject)
+		if err != nil {
+			return err
+		}
+		if total >= entity.MaxAddressesPerUser {
+			res = &address.CreateAddressResponse{
+				Base: utils.BadRequestResponse(fmt.Sprintf("Maximum %d addresses per user", entity.MaxAddressesPerUser)),
+			}
+			return nil
+		}
+
+		newAddress := fromAddressInput(request.Address)
+		newAddress.Id = uuid.NewString()
+		newAddress.UserId = claims.Subject
+		newAddress.CreatedAt = time.Now()
+		newAddress.CreatedBy = claims.Subject
+		// alamat pertama otomatis menjadi default
+		if total == 0 {
+			newAddress.IsDefaultShipping = true
+			newAddress.IsDefaultBilling = true
+		}
+		err = s.addressRepository.UnsetDefaultAddresses(ctx, claims.Subject, newAddress.IsDefaultShipping, newAddress.IsDefaultBilling, claims.Subject)
+		if err != nil {
+			return err
+		}
+		err = s.addressRepository.InsertAddress(ctx, newAddress)
+		if err != nil {
+			return err
+		}
+
+		res = &address.CreateAddressResponse{
+			Base:      utils.SuccessResponse("Create Address Success"),
+			AddressId: newAddress.Id,
+		}
+		return nil
+	}, database.WithIsolation(sql.LevelSerializable))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *addressService) ListAddresses(ctx context.Context, request *address.ListAddressesRequest) (*address.ListAddressesResponse, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.ListAddresses")
+	defer span.End()
+
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := s.addressRepository.ListAddressesByUserId(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*address.Address, 0, len(addresses))
+	for _, item := range addresses {
+		res = append(res, toAddressResponse(item))
+	}
+
+	return &address.ListAddressesResponse{
+		Base:      utils.SuccessResponse("List Addresses Success"),
+		Addresses: res,
+	}, nil
+}
+
+func (s *addressService) GetAddress(ctx context.Context, request *address.GetAddressRequest) (*address.GetAddressResponse, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.GetAddress")
+	defer span.End()
+
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.addressRepository.GetAddressById(ctx, claims.Subject, request.AddressId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return &address.GetAddressResponse{
+			Base: utils.BadRequestResponse("Address is not found"),
+		}, nil
+	}
+
+	return &address.GetAddressResponse{
+		Base:    utils.SuccessResponse("Get Address Success"),
+		Address: toAddressResponse(existing),
+	}, nil
+}
+
+func (s *addressService) UpdateAddress(ctx context.Context, request *address.UpdateAddressRequest) (*address.UpdateAddressResponse, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.UpdateAddress")
+	defer span.End()
+
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.addressRepository.GetAddressById(ctx, claims.Subject, request.AddressId)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return &address.UpdateAddressResponse{
+			Base: utils.BadRequestResponse("Address is not found"),
+		}, nil
+	}
+
+	// default hanya bisa dipindah dengan menjadikan alamat lain default,
+	// agar user yang punya alamat selalu punya alamat default
+	if existing.IsDefaultShipping && !request.Address.IsDefaultShipping {
+		return &address.UpdateAddressResponse{
+			Base: utils.BadRequestResponse("Default shipping address cannot be unset, set another address as default instead"),
+		}, nil
+	}
+	if existing.IsDefaultBilling && !request.Address.IsDefaultBilling {
+		return &address.UpdateAddressResponse{
+			Base: utils.BadRequestResponse("Default billing address cannot be unset, set another address as default instead"),
+		}, nil
+	}
+
+	updated := fromAddressInput(request.Address)
+	updated.Id = existing.Id
+	updated.UserId = existing.UserId
+	updated.UpdatedBy = claims.Subject
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.addressRepository.UnsetDefaultAddresses(ctx, claims.Subject, updated.IsDefaultShipping && !existing.IsDefaultShipping, updated.IsDefaultBilling && !existing.IsDefaultBilling, claims.Subject)
+		if err != nil {
+			return err
+		}
+		return s.addressRepository.UpdateAddress(ctx, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &address.UpdateAddressResponse{
+		Base: utils.SuccessResponse("Update Address Success"),
+	}, nil
+}
+
+func (s *addressService) DeleteAddress(ctx context.Context, request *address.DeleteAddressRequest) (*address.DeleteAddressResponse, error) {
+	ctx, span := tracing.Start(ctx, "AddressService.DeleteAddress")
+	defer span.End()
+
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *address.DeleteAddressResponse
+	// serializable agar alamat yang dipromosikan tidak sedang dihapus request lain
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := s.addressRepository.GetAddressById(ctx, claims.Subject, request.AddressId)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			res = &address.DeleteAddressResponse{
+				Base: utils.BadRequestResponse("Address is not found"),
+			}
+			return nil
+		}
+
+		// soft delete agar alamat tetap bisa dirujuk, order nantinya menyimpan AddressSnapshot
+		err = s.addressRepository.SoftDeleteAddress(ctx, claims.Subject, existing.Id, claims.Subject)
+		if err != nil {
+			return err
+		}
+		// user yang masih punya alamat harus tetap punya alamat default
+		if existing.IsDefaultShipping || existing.IsDefaultBilling {
+			err = s.addressRepository.PromoteDefaultAddress(ctx, claims.Subject, existing.IsDefaultShipping, existing.IsDefaultBilling, claims.Subject)
+			if err != nil {
+				return err
+			}
+		}
+
+		res = &address.DeleteAddressResponse{
+			Base: utils.SuccessResponse("Delete Address Success"),
+		}
+		return nil
+	}, database.WithIsolation(sql.LevelSerializable))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func fromAddressInput(input *address.AddressInput) *entity.Address {
+	res := &entity.Address{
+		Label:             input.Label,
+		RecipientName:     input.RecipientName,
+		PhoneNumber:       input.PhoneNumber,
+		StreetAddress:     input.StreetAddress,
+		Province:          input.Province,
+		City:              input.City,
+		District:          input.District,
+		SubDistrict:       input.SubDistrict,
+		PostalCode:        input.PostalCode,
+		Notes:             input.Notes,
+		IsDefaultShipping: input.IsDefaultShipping,
+		IsDefaultBilling:  input.IsDefaultBilling,
+	}
+	if input.Location != nil {
+		res.Location = &entity.GeoPoint{
+			Latitude:  input.Location.Latitude,
+			Longitude: input.Location.Longitude,
+		}
+	}
+	return res
+}
+
+func toAddressResponse(item *entity.Address) *address.Address {
+	res := &address.Address{
+		AddressId:         item.Id,
+		Label:             item.Label,
+		RecipientName:     item.RecipientName,
+		PhoneNumber:       item.PhoneNumber,
+		StreetAddress:     item.StreetAddress,
+		Province:          item.Province,
+		City:              item.City,
+		District:          item.District,
+		SubDistrict:       item.SubDistrict,
+		PostalCode:        item.PostalCode,
+		Notes:             item.Notes,
+		IsDefaultShipping: item.IsDefaultShipping,
+		IsDefaultBilling:  item.IsDefaultBilling,
+		CreatedAt:         timestamppb.New(item.CreatedAt),
+	}
+	if item.Location != nil {
+		res.Location = &address.GeoPoint{
+			Latitude:  item.Location.Latitude,
+			Longitude: item.Location.Longitude,
+		}
+	}
+	if !item.UpdatedAt.IsZero() {
+		res.UpdatedAt = timestamppb.New(item.UpdatedAt)
+	}
+	return res
+}
+
+func NewAddressService(txManager database.ITransactionManager, addressRepository repository.IAddressRepository) IAddressService {
+	return &addressService{
+		txManager:         txManager,
+		addressRepository: addressRepository,
+	}
+}
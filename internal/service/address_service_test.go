@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/address"
+)
+
+type fakeAddressRepository struct {
+	repository.IAddressRepository
+	addresses map[string]*entity.Address
+}
+
+func (r *fakeAddressRepository) GetAddressById(ctx context.Context, userId string, addressId string) (*entity.Address, error) {
+	existing, ok := r.addresses[addressId]
+	if !ok || existing.UserId != userId {
+		return nil, nil
+	}
+	// salinan, seperti hasil scan dari database
+	copied := *existing
+	return &copied, nil
+}
+
+func (r *fakeAddressRepository) UnsetDefaultAddresses(ctx context.Context, userId string, shipping bool, billing bool, updatedBy string) error {
+	for _, existing := range r.addresses {
+		if existing.UserId != userId {
+			continue
+		}
+		existing.IsDefaultShipping = existing.IsDefaultShipping && !shipping
+		existing.IsDefaultBilling = existing.IsDefaultBilling && !billing
+	}
+	return nil
+}
+
+func (r *fakeAddressRepository) UpdateAddress(ctx context.Context, updated *entity.Address) error {
+	r.addresses[updated.Id] = updated
+	return nil
+}
+
+func (r *fakeAddressRepository) SoftDeleteAddress(ctx context.Context, userId string, addressId string, deletedBy string) error {
+	existing := r.addresses[addressId]
+	existing.IsDeleted = true
+	existing.IsDefaultShipping, existing.IsDefaultBilling = false, false
+	return nil
+}
+
+func (r *fakeAddressRepository) PromoteDefaultAddress(ctx context.Context, userId string, shipping bool, billing bool, updatedBy string) error {
+	var newest *entity.Address
+	for _, existing := range r.addresses {
+		if existing.UserId == userId && !existing.IsDeleted && (newest == nil || existing.CreatedAt.After(newest.CreatedAt)) {
+			newest = existing
+		}
+	}
+	if newest != nil {
+		newest.IsDefaultShipping = newest.IsDefaultShipping || shipping
+		newest.IsDefaultBilling = newest.IsDefaultBilling || billing
+	}
+	return nil
+}
+
+func TestUpdateAddressDefaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		addressId    string
+		shipping     bool
+		billing      bool
+		wantStatus   int64
+		wantShipping string
+		wantBilling  string
+	}{
+		{name: "keep defaults", addressId: "home", shipping: true, billing: true, wantStatus: 200, wantShipping: "home", wantBilling: "home"},
+		{name: "unset only default shipping", addressId: "home", shipping: false, billing: true, wantStatus: 400, wantShipping: "home", wantBilling: "home"},
+		{name: "unset only default billing", addressId: "home", shipping: true, billing: false, wantStatus: 400, wantShipping: "home", wantBilling: "home"},
+		{name: "move default shipping", addressId: "office", shipping: true, billing: false, wantStatus: 200, wantShipping: "office", wantBilling: "home"},
+		{name: "move both defaults", addressId: "office", shipping: true, billing: true, wantStatus: 200, wantShipping: "office", wantBilling: "office"},
+		{name: "non default stays non default", addressId: "office", shipping: false, billing: false, wantStatus: 200, wantShipping: "home", wantBilling: "home"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAddressRepository{addresses: map[string]*entity.Address{
+				"home":   {Id: "home", UserId: "user-1", IsDefaultShipping: true, IsDefaultBilling: true},
+				"office": {Id: "office", UserId: "user-1"},
+			}}
+			svc := &addressService{txManager: fakeTransactionManager{}, addressRepository: repo}
+			claims := &jwtentity.JwtClaims{}
+			claims.Subject = "user-1"
+
+			res, err := svc.UpdateAddress(claims.SetToContext(context.Background()), &address.UpdateAddressRequest{
+				AddressId: tt.addressId,
+				Address: &address.AddressInput{
+					Label:             tt.addressId,
+					IsDefaultShipping: tt.shipping,
+					IsDefaultBilling:  tt.billing,
+				},
+			})
+			if err != nil {
+				t.Fatalf("UpdateAddress() error = %v", err)
+			}
+			if res.Base.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", res.Base.StatusCode, res.Base.Message, tt.wantStatus)
+			}
+			var shipping, billing []string
+			for id, existing := range repo.addresses {
+				if existing.IsDefaultShipping {
+					shipping = append(shipping, id)
+				}
+				if existing.IsDefaultBilling {
+					billing = append(billing, id)
+				}
+			}
+			if len(shipping) != 1 || shipping[0] != tt.wantShipping {
+				t.Errorf("default shipping = %v, want [%s]", shipping, tt.wantShipping)
+			}
+			if len(billing) != 1 || billing[0] != tt.wantBilling {
+				t.Errorf("default billing = %v, want [%s]", billing, tt.wantBilling)
+			}
+		})
+	}
+}
+
+func TestDeleteAddressDefaults(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		addresses    map[string]*entity.Address
+		addressId    string
+		wantShipping string
+		wantBilling  string
+	}{
+		{
+			name: "newest address becomes default",
+			addresses: map[string]*entity.Address{
+				"home":      {Id: "home", UserId: "user-1", IsDefaultShipping: true, IsDefaultBilling: true, CreatedAt: now.Add(-time.Hour * 2)},
+				"office":    {Id: "office", UserId: "user-1", CreatedAt: now.Add(-time.Hour)},
+				"warehouse": {Id: "warehouse", UserId: "user-1", CreatedAt: now},
+			},
+			addressId:    "home",
+			wantShipping: "warehouse",
+			wantBilling:  "warehouse",
+		},
+		{
+			name: "only deleted default is replaced",
+			addresses: map[string]*entity.Address{
+				"home":   {Id: "home", UserId: "user-1", IsDefaultShipping: true, CreatedAt: now.Add(-time.Hour)},
+				"office": {Id: "office", UserId: "user-1", IsDefaultBilling: true, CreatedAt: now},
+			},
+			addressId:    "home",
+			wantShipping: "office",
+			wantBilling:  "office",
+		},
+		{
+			name: "non default keeps defaults",
+			addresses: map[string]*entity.Address{
+				"home":   {Id: "home", UserId: "user-1", IsDefaultShipping: true, IsDefaultBilling: true, CreatedAt: now.Add(-time.Hour)},
+				"office": {Id: "office", UserId: "user-1", CreatedAt: now},
+			},
+			addressId:    "office",
+			wantShipping: "home",
+			wantBilling:  "home",
+		},
+		{
+			name: "last address",
+			addresses: map[string]*entity.Address{
+				"home": {Id: "home", UserId: "user-1", IsDefaultShipping: true, IsDefaultBilling: true, CreatedAt: now},
+			},
+			addressId: "home",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAddressRepository{addresses: tt.addresses}
+			svc := &addressService{txManager: fakeTransactionManager{}, addressRepository: repo}
+			claims := &jwtentity.JwtClaims{}
+			claims.Subject = "user-1"
+
+			res, err := svc.DeleteAddress(claims.SetToContext(context.Background()), &address.DeleteAddressRequest{AddressId: tt.addressId})
+			if err != nil {
+				t.Fatalf("DeleteAddress() error = %v", err)
+			}
+			if res.Base.StatusCode != 200 {
+				t.Fatalf("status = %d (%s), want 200", res.Base.StatusCode, res.Base.Message)
+			}
+			var shipping, billing string
+			for id, existing := range repo.addresses {
+				if existing.IsDefaultShipping {
+					shipping = id
+				}
+				if existing.IsDefaultBilling {
+					billing = id
+				}
+			}
+			if shipping != tt.wantShipping || billing != tt.wantBilling {
+				t.Errorf("defaults = %q, %q, want %q, %q", shipping, billing, tt.wantShipping, tt.wantBilling)
+			}
+		})
+	}
+}
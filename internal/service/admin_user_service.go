@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/audit"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/admin"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/mailer"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type IAdminUserService interface {
+	ListUsers(ctx context.Context, request *admin.ListUsersRequest) (*admin.ListUsersResponse, error)
+	GetUser(ctx context.Context, request *admin.GetUserRequest) (*admin.GetUserResponse, error)
+	ChangeUserRole(ctx context.Context, request *admin.ChangeUserRoleRequest) (*admin.ChangeUserRoleResponse, error)
+	DisableUser(ctx context.Context, request *admin.DisableUserRequest) (*admin.DisableUserResponse, error)
+	EnableUser(ctx context.Context, request *admin.EnableUserRequest) (*admin.EnableUserResponse, error)
+	ForcePasswordReset(ctx context.Context, request *admin.ForcePasswordResetRequest) (*admin.ForcePasswordResetResponse, error)
+	RestoreUser(ctx context.Context, request *admin.RestoreUserRequest) (*admin.RestoreUserResponse, error)
+	Impersonate(ctx context.Context, request *admin.ImpersonateRequest) (*admin.ImpersonateResponse, error)
+}
+
+type adminUserService struct {
+	txManager           database.ITransactionManager
+	adminUserRepository repository.IAdminUserRepository
+	authRepository      repository.IAuthRepository
+	auditLogger         audit.IAuditLogger
+	cacheService        *gocache.Cache
+	mailer              mailer.IMailer
+	keyManager          *jwtentity.KeyManager
+}
+
+func (s *adminUserService) ListUsers(ctx context.Context, request *admin.ListUsersRequest) (*admin.ListUsersResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserService.ListUsers")
+	defer span.End()
+
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page, pageSize := utils.NormalizePagination(request.Pagination)
+	filter := entity.UserFilter{
+		RoleCode: request.RoleCode,
+		Search:   request.Search,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if request.CreatedFrom != nil {
+		filter.CreatedFrom = request.CreatedFrom.AsTime()
+	}
+	if request.CreatedTo != nil {
+		filter.CreatedTo = request.CreatedTo.AsTime()
+	}
+	switch request.DeletedStatus {
+	case admin.DeletedStatusFilter_DELETED_STATUS_FILTER_DELETED:
+		filter.DeletedStatus = entity.UserDeletedStatusDeleted
+	case admin.DeletedStatusFilter_DELETED_STATUS_FILTER_ALL:
+		filter.DeletedStatus = entity.UserDeletedStatusAll
+	default:
+		filter.DeletedStatus = entity.UserDeletedStatusActive
+	}
+
+	users, total, err := s.adminUserRepository.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	adminUsers := make([]*admin.AdminUser, 0, len(users))
+	for _, user := range users {
+		adminUsers = append(adminUsers, toAdminUser(user))
+	}
+
+	return &admin.ListUsersResponse{
+		Base:       utils.SuccessResponse("List Users Success"),
+		Pagination: utils.PaginationResponse(page, pageSize, total),
+		Users:      adminUsers,
+	}, nil
+}
+
+func (s *adminUserService) GetUser(ctx context.Context, request *admin.GetUserRequest) (*admin.GetUserResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserService.GetUser")
+	defer span.End()
+
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	user, err := s.adminUserRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return &admin.GetUserResponse{
+			Base: utils.BadRequestResponse("User is not found"),
+		}, nil
+	}
+
+	return &admin.GetUserResponse{
+		Base: utils.SuccessResponse("Get User Success"),
+		User: toAdminUser(user),
+	}, nil
+}
+
+func (s *adminUserService) ChangeUserRole(ctx context.Context, request *admin.ChangeUserRoleRequest) (*admin.ChangeUserRoleResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserService.ChangeUserRole")
+	defer span.End()
+
+	claims, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if request.UserId == claims.Subject {
+		return &admin.ChangeUserRoleResponse{
+			Base: utils.BadRequestResponse("Cannot change your own role"),
+		}, nil
+	}
+	user, err := s.adminUserRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDeleted {
+		return &admin.ChangeUserRoleResponse{
+			Base: utils.BadRequestResponse("User is not found"),
+		}, nil
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.adminUserRepository.UpdateUserRole(ctx, user.Id, request.RoleCode, claims.Subject)
+		if err != nil {
+			return err
+		}
+		err = s.authRepository.RevokeUserTokens(ctx, user.Id, time.Now())
+		if err != nil {
+			return err
+		}
+		return s.auditLogger.Record(ctx, audit.Entry{
+			Action:     entity.AuditActionUserChangeRole,
+			TargetType: entity.AuditTargetUser,
+			TargetId:   user.Id,
+			Before:     map[string]any{"role_code": user.RoleCode},
+			After:      map[string]any{"role_code": request.RoleCode},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	// role ada di dalam token, token lama harus login ulang
+	jwtentity.ForgetUserState(s.cacheService, user.Id)
+
+	return &admin.ChangeUserRoleResponse{
+		Base: utils.SuccessResponse("Change User Role Success"),
+	}, nil
+}
+
+func (s *adminUserService) DisableUser(ctx context.Context, request *admin.DisableUserRequest) (*admin.DisableUserResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserService.DisableUser")
+	defer span.End()
+
+	claims, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if request.UserId == claims.Subject {
+		return &admin.DisableUserResponse{
+			Base: utils.BadRequestResponse("Cannot disable your own account"),
+		}, nil
+	}
+	user, err := s.adminUserRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDeleted {
+		return &admin.DisableUserResponse{
+			Base: utils.BadRequestResponse("User is not found"),
+		}, nil
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.adminUserRepository.UpdateUserDisabled(ctx, user.Id, true, claims.Subject)
+		if err != nil {
+			return err
+		}
+		err = s.authRepository.RevokeUserTokens(ctx, user.Id, time.Now())
+		if err != nil {
+			return err
+		}
+		return s.auditLogger.Record(ctx, audit.Entry{
+			Action:     entity.AuditActionUserDisable,
+			TargetType: entity.AuditTargetUser,
+			TargetId:   user.Id,
+			Before:     map[string]any{"is_disabled": user.IsDisabled},
+			After:      map[string]any{"is_disabled": true},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	jwtentity.ForgetUserState(s.cacheService, user.Id)
+
+	return &admin.DisableUserResponse{
+		Base: utils.SuccessResponse("Disable User Success"),
+	}, nil
+}
+
+func (s *adminUserService) EnableUser(ctx context.Context, request *admin.EnableUserRequest) (*admin.EnableUserResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserService.EnableUser")
+	defer span.End()
+
+	claims, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.adminUserRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDeleted {
+		return &admin.EnableUserResponse{
+			Base: utils.BadRequestResponse("User is not found"),
+		}, nil
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.adminUserRepository.UpdateUserDisabled(ctx, user.Id, false, claims.Subject)
+		if err != nil {
+			return err
+		}
+		return s.auditLogger.Record(ctx, audit.Entry{
+			Action:     entity.AuditActionUserEnable,
+			TargetType: entity.AuditTargetUser,
+			TargetId:   user.Id,
+			Before:     map[string]any{"is_disabled": user.IsDisabled},
+			After:      map[string]any{"is_disabled": false},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	jwtentity.ForgetUserState(s.cacheService, user.Id)
+
+	return &admin.EnableUserResponse{
+		Base: utils.SuccessResponse("Enable User Success"),
+	}, nil
+}
+
+func (s *adminUserService) ForcePasswordReset(ctx context.Context, request *admin.ForcePasswordResetRequest) (*admin.ForcePasswordResetResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserService.ForcePasswordReset")
+	defer span.End()
+
+	claims, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.adminUserRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDeleted {
+		return &admin.ForcePasswordResetResponse{
+			Base: utils.BadRequestResponse("User is not found"),
+		}, nil
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.adminUserRepository.UpdateUserPasswordResetRequired(ctx, user.Id, claims.Subject)
+		if err != nil {
+			return err
+		}
+		err = s.authRepository.RevokeUserTokens(ctx, user.Id, time.Now())
+		if err != nil {
+			return err
+		}
+		return s.auditLogger.Record(ctx, audit.Entry{
+			Action:     entity.AuditActionUserForcePasswordReset,
+			TargetType: entity.AuditTargetUser,
+			TargetId:   user.Id,
+			Before:     map[string]any{"password_reset_required": user.PasswordResetRequired},
+			After:      map[string]any{"password_reset_required": true},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	jwtentity.ForgetUserState(s.cacheService, user.Id)
+	err = sendPasswordResetToken(ctx, s.authRepository, s.mailer, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin.ForcePasswordResetResponse{
+		Base: utils.SuccessResponse("Force Password Reset Success"),
+	}, nil
+}
+
+func (s *adminUserService) RestoreUser(ctx context.Context, request *admin.RestoreUserRequest) (*admin.RestoreUserResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserService.RestoreUser")
+	defer span.End()
+
+	claims, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.adminUserRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return &admin.RestoreUserResponse{
+			Base: utils.BadRequestResponse("User is not found"),
+		}, nil
+	}
+	if !user.IsDeleted {
+		return &admin.RestoreUserResponse{
+			Base: utils.BadRequestResponse("User is not deleted"),
+		}, nil
+	}
+	if time.Since(user.DeletedAt) >= entity.AccountDeletionGracePeriod {
+		return &admin.RestoreUserResponse{
+			Base: utils.BadRequestResponse("User data is already anonymized"),
+		}, nil
+	}
+	// email bisa saja sudah dipakai user baru setelah akun ini dihapus
+	existingUser, err := s.authRepository.GetUserByEmail(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existingUser != nil {
+		return &admin.RestoreUserResponse{
+			Base: utils.BadRequestResponse("Email already used by another user"),
+		}, nil
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.adminUserRepository.RestoreUser(ctx, user.Id, claims.Subject)
+		if err != nil {
+			return err
+		}
+		return s.auditLogger.Record(ctx, audit.Entry{
+			Action:     entity.AuditActionUserRestore,
+			TargetType: entity.AuditTargetUser,
+			TargetId:   user.Id,
+			Before:     map[string]any{"is_deleted": true},
+			After:      map[string]any{"is_deleted": false},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	jwtentity.ForgetUserState(s.cacheService, user.Id)
+
+	return &admin.RestoreUserResponse{
+		Base: utils.SuccessResponse("Restore User Success"),
+	}, nil
+}
+
+// Impersonate menerbitkan token singkat atas nama customer untuk kebutuhan support.
+// Token membawa claim act berisi admin yang melakukan impersonation.
+func (s *adminUserService) Impersonate(ctx context.Context, request *admin.ImpersonateRequest) (*admin.ImpersonateResponse, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserService.Impersonate")
+	defer span.End()
+
+	claims, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if request.UserId == claims.Subject {
+		return &admin.ImpersonateResponse{
+			Base: utils.BadRequestResponse("Cannot impersonate yourself"),
+		}, nil
+	}
+	user, err := s.adminUserRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDeleted {
+		return &admin.ImpersonateResponse{
+			Base: utils.BadRequestResponse("User is not found"),
+		}, nil
+	}
+	if user.RoleCode != entity.UserRoleCustomer {
+		return &admin.ImpersonateResponse{
+			Base: utils.BadRequestResponse("Only customer can be impersonated"),
+		}, nil
+	}
+	if user.IsDisabled {
+		return &admin.ImpersonateResponse{
+			Base: utils.BadRequestResponse("User is disabled"),
+		}, nil
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(jwtentity.ImpersonationTokenTTL)
+	accessToken, err := s.keyManager.SignClaims(jwtentity.JwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "ecommerce-furniture",
+			Subject:   user.Id,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Email:    user.Email,
+		FullName: user.FullName,
+		Role:     user.RoleCode,
+		Act: &jwtentity.ActorClaims{
+			Subject:  claims.Subject,
+			FullName: claims.FullName,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.auditLogger.Record(ctx, audit.Entry{
+		Action:     entity.AuditActionUserImpersonate,
+		TargetType: entity.AuditTargetUser,
+		TargetId:   user.Id,
+		After: map[string]any{
+			"reason":     request.Reason,
+			"expires_at": expiresAt,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin.ImpersonateResponse{
+		Base:        utils.SuccessResponse("Impersonate Success"),
+		AccessToken: accessToken,
+		ExpiresAt:   timestamppb.New(expiresAt),
+	}, nil
+}
+
+func requireAdmin(ctx context.Context) (*jwtentity.JwtClaims, error) {
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Role != entity.UserRoleAdmin {
+		return nil, utils.PermissionDeniedResponse()
+	}
+	return claims, nil
+}
+
+func toAdminUser(user *entity.User) *admin.AdminUser {
+	adminUser := &admin.AdminUser{
+		UserId:                user.Id,
+		FullName:              user.FullName,
+		Email:                 user.Email,
+		PhoneNumber:           user.PhoneNumber,
+		RoleCode:              user.RoleCode,
+		IsDisabled:            user.IsDisabled,
+		IsDeleted:             user.IsDeleted,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             timestamppb.New(user.CreatedAt),
+	}
+	if !user.UpdatedAt.IsZero() {
+		adminUser.UpdatedAt = timestamppb.New(user.UpdatedAt)
+	}
+	if user.IsDeleted {
+		adminUser.DeletedAt = timestamppb.New(user.DeletedAt)
+	}
+	return adminUser
+}
+
+func NewAdminUserService(txManager database.ITransactionManager, adminUserRepository repository.IAdminUserRepository, authRepository repository.IAuthRepository, auditLogger audit.IAuditLogger, cacheService *gocache.Cache, mailer mailer.IMailer, keyManager *jwtentity.KeyManager) IAdminUserService {
+	return &adminUserService{
+		txManager:           txManager,
+		adminUserRepository: adminUserRepository,
+		authRepository:      authRepository,
+		auditLogger:         auditLogger,
+		cacheService:        cacheService,
+		mailer:              mailer,
+		keyManager:          keyManager,
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/audit"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/admin"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type IApiKeyService interface {
+	CreateServiceAccount(ctx context.Context, request *admin.CreateServiceAccountRequest) (*admin.CreateServiceAccountResponse, error)
+	CreateApiKey(ctx context.Context, request *admin.CreateApiKeyRequest) (*admin.CreateApiKeyResponse, error)
+	ListApiKeys(ctx context.Context, request *admin.ListApiKeysRequest) (*admin.ListApiKeysResponse, error)
+	RevokeApiKey(ctx context.Context, request *admin.RevokeApiKeyRequest) (*admin.RevokeApiKeyResponse, error)
+}
+
+type apiKeyService struct {
+	txManager        database.ITransactionManager
+	apiKeyRepository repository.IApiKeyRepository
+	authRepository   repository.IAuthRepository
+	auditLogger      audit.IAuditLogger
+}
+
+func (s *apiKeyService) CreateServiceAccount(ctx context.Context, request *admin.CreateServiceAccountRequest) (*admin.CreateServiceAccountResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.CreateServiceAccount")
+	defer span.End()
+
+	claims, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authRepository.GetUserByEmail(ctx, request.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return &admin.CreateServiceAccountResponse{
+			Base: utils.BadRequestResponse("User already exist"),
+		}, nil
+	}
+
+	// password kosong, service account tidak bisa login lewat Login
+	newUser := entity.User{
+		Id:        uuid.NewString(),
+		FullName:  request.FullName,
+		Email:     request.Email,
+		RoleCode:  entity.UserRoleServiceAccount,
+		CreatedAt: time.Now(),
+		CreatedBy: claims.Subject,
+	}
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.authRepository.InsertUser(ctx, &newUser)
+		if err != nil {
+			return err
+		}
+		return s.auditLogger.Record(ctx, audit.Entry{
+			Action:     entity.AuditActionServiceAccountCreate,
+			TargetType: entity.AuditTargetUser,
+			TargetId:   newUser.Id,
+			After: map[string]any{
+				"full_name": newUser.FullName,
+				"email":     newUser.Email,
+				"role_code": newUser.RoleCode,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin.CreateServiceAccountResponse{
+		Base:   utils.SuccessResponse("Create Service Account Success"),
+		UserId: newUser.Id,
+	}, nil
+}
+
+func (s *apiKeyService) CreateApiKey(ctx context.Context, request *admin.CreateApiKeyRequest) (*admin.CreateApiKeyResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.CreateApiKey")
+	defer span.End()
+
+	claims, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return &admin.CreateApiKeyResponse{
+			Base: utils.BadRequestResponse("User is not found"),
+		}, nil
+	}
+	if user.RoleCode != entity.UserRoleServiceAccount {
+		return &admin.CreateApiKeyResponse{
+			Base: utils.BadRequestResponse("API key can only be created for service account"),
+		}, nil
+	}
+
+	prefix, err := utils.GenerateRandomToken(6)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	apiKey := entity.ApiKey{
+		Id:         uuid.NewString(),
+		UserId:     user.Id,
+		Name:       request.Name,
+		Prefix:     prefix,
+		SecretHash: jwtentity.HashApiKeySecret(secret),
+		Scopes:     request.Scopes,
+		CreatedAt:  time.Now(),
+		CreatedBy:  claims.Subject,
+	}
+	if request.ExpiresAt != nil {
+		apiKey.ExpiresAt = request.ExpiresAt.AsTime()
+	}
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.apiKeyRepository.InsertApiKey(ctx, &apiKey)
+		if err != nil {
+			return err
+		}
+		return s.auditLogger.Record(ctx, audit.Entry{
+			Action:     entity.AuditActionApiKeyCreate,
+			TargetType: entity.AuditTargetApiKey,
+			TargetId:   apiKey.Id,
+			After: map[string]any{
+				"user_id":    apiKey.UserId,
+				"name":       apiKey.Name,
+				"prefix":     apiKey.Prefix,
+				"scopes":     apiKey.Scopes,
+				"expires_at": apiKey.ExpiresAt,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin.CreateApiKeyResponse{
+		Base:     utils.SuccessResponse("Create API Key Success"),
+		ApiKeyId: apiKey.Id,
+		ApiKey:   entity.ApiKeyPrefixTag + "_" + prefix + "_" + secret,
+	}, nil
+}
+
+func (s *apiKeyService) ListApiKeys(ctx context.Context, request *admin.ListApiKeysRequest) (*admin.ListApiKeysResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.ListApiKeys")
+	defer span.End()
+
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page, pageSize := utils.NormalizePagination(request.Pagination)
+	apiKeys, total, err := s.apiKeyRepository.ListApiKeys(ctx, entity.ApiKeyFilter{
+		UserId:   request.UserId,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*admin.ApiKey, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		res = append(res, toAdminApiKey(apiKey))
+	}
+
+	return &admin.ListApiKeysResponse{
+		Base:       utils.SuccessResponse("List API Keys Success"),
+		Pagination: utils.PaginationResponse(page, pageSize, total),
+		ApiKeys:    res,
+	}, nil
+}
+
+func (s *apiKeyService) RevokeApiKey(ctx context.Context, request *admin.RevokeApiKeyRequest) (*admin.RevokeApiKeyResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.RevokeApiKey")
+	defer span.End()
+
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	apiKey, err := s.apiKeyRepository.GetApiKeyById(ctx, request.ApiKeyId)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil {
+		return &admin.RevokeApiKeyResponse{
+			Base: utils.BadRequestResponse("API key is not found"),
+		}, nil
+	}
+	if !apiKey.RevokedAt.IsZero() {
+		return &admin.RevokeApiKeyResponse{
+			Base: utils.BadRequestResponse("API key is already revoked"),
+		}, nil
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.apiKeyRepository.RevokeApiKey(ctx, apiKey.Id)
+		if err != nil {
+			return err
+		}
+		return s.auditLogger.Record(ctx, audit.Entry{
+			Action:     entity.AuditActionApiKeyRevoke,
+			TargetType: entity.AuditTargetApiKey,
+			TargetId:   apiKey.Id,
+			Before:     map[string]any{"revoked": false},
+			After:      map[string]any{"revoked": true},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin.RevokeApiKeyResponse{
+		Base: utils.SuccessResponse("Revoke API Key Success"),
+	}, nil
+}
+
+func toAdminApiKey(apiKey *entity.ApiKey) *admin.ApiKey {
+	res := &admin.ApiKey{
+		ApiKeyId:  apiKey.Id,
+		UserId:    apiKey.UserId,
+		Name:      apiKey.Name,
+		Prefix:    entity.ApiKeyPrefixTag + "_" + apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		CreatedAt: timestamppb.New(apiKey.CreatedAt),
+	}
+	if !apiKey.ExpiresAt.IsZero() {
+		res.ExpiresAt = timestamppb.New(apiKey.ExpiresAt)
+	}
+	if !apiKey.LastUsedAt.IsZero() {
+		res.LastUsedAt = timestamppb.New(apiKey.LastUsedAt)
+	}
+	if !apiKey.RevokedAt.IsZero() {
+		res.RevokedAt = timestamppb.New(apiKey.RevokedAt)
+	}
+	return res
+}
+
+func NewApiKeyService(txManager database.ITransactionManager, apiKeyRepository repository.IApiKeyRepository, authRepository repository.IAuthRepository, auditLogger audit.IAuditLogger) IApiKeyService {
+	return &apiKeyService{
+		txManager:        txManager,
+		apiKeyRepository: apiKeyRepository,
+		authRepository:   authRepository,
+		auditLogger:      auditLogger,
+	}
+}
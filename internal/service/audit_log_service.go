@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/admin"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type IAuditLogService interface {
+	ListAuditLogs(ctx context.Context, request *admin.ListAuditLogsRequest) (*admin.ListAuditLogsResponse, error)
+}
+
+type auditLogService struct {
+	auditLogRepository repository.IAuditLogRepository
+}
+
+func (s *auditLogService) ListAuditLogs(ctx context.Context, request *admin.ListAuditLogsRequest) (*admin.ListAuditLogsResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.ListAuditLogs")
+	defer span.End()
+
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page, pageSize := utils.NormalizePagination(request.Pagination)
+	filter := entity.AuditLogFilter{
+		ActorId:        request.ActorId,
+		Action:         request.Action,
+		TargetType:     request.TargetType,
+		TargetId:       request.TargetId,
+		ImpersonatorId: request.ImpersonatorId,
+		Page:           page,
+		PageSize:       pageSize,
+	}
+	if request.CreatedFrom != nil {
+		filter.CreatedFrom = request.CreatedFrom.AsTime()
+	}
+	if request.CreatedTo != nil {
+		filter.CreatedTo = request.CreatedTo.AsTime()
+	}
+
+	auditLogs, total, err := s.auditLogRepository.ListAuditLogs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*admin.AuditLog, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		changes := make([]*admin.AuditChange, 0, len(auditLog.Changes))
+		for _, change := range auditLog.Changes {
+			before, err := json.Marshal(change.Before)
+			if err != nil {
+				return nil, err
+			}
+			after, err := json.Marshal(change.After)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, &admin.AuditChange{
+				Field:  change.Field,
+				Before: string(before),
+				After:  string(after),
+			})
+		}
+		res = append(res, &admin.AuditLog{
+			Id:             auditLog.Id,
+			ActorId:        auditLog.ActorId,
+			Action:         auditLog.Action,
+			TargetType:     auditLog.TargetType,
+			TargetId:       auditLog.TargetId,
+			Changes:        changes,
+			RequestId:      auditLog.RequestId,
+			IpAddress:      auditLog.IpAddress,
+			Method:         auditLog.Method,
+			CreatedAt:      timestamppb.New(auditLog.CreatedAt),
+			ImpersonatorId: auditLog.ImpersonatorId,
+		})
+	}
+
+	return &admin.ListAuditLogsResponse{
+		Base:       utils.SuccessResponse("List Audit Logs Success"),
+		Pagination: utils.PaginationResponse(page, pageSize, total),
+		AuditLogs:  res,
+	}, nil
+}
+
+func NewAuditLogService(auditLogRepository repository.IAuditLogRepository) IAuditLogService {
+	return &auditLogService{
+		auditLogRepository: auditLogRepository,
+	}
+}
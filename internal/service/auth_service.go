@@ -2,8 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"os"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,10 +12,17 @@ import (
 	gocache "github.com/patrickmn/go-cache"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
 	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/oidclogin"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/passwordhash"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/passwordpolicy"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
 	auth "github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/auth"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/mailer"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/metrics"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -26,19 +34,70 @@ type IAuthService interface {
 	Logout(ctx context.Context, request *auth.LogoutRequest) (*auth.LogoutResponse, error)
 	ChangePassword(ctx context.Context, request *auth.ChangePasswordRequest) (*auth.ChangePasswordResponse, error)
 	GetProfile(ctx context.Context, request *auth.GetProfileRequest) (*auth.GetProfileResponse, error)
+	GetJwks(ctx context.Context, request *auth.GetJwksRequest) (*auth.GetJwksResponse, error)
+	UpdateProfile(ctx context.Context, request *auth.UpdateProfileRequest) (*auth.UpdateProfileResponse, error)
+	ChangeEmail(ctx context.Context, request *auth.ChangeEmailRequest) (*auth.ChangeEmailResponse, error)
+	ConfirmChangeEmail(ctx context.Context, request *auth.ConfirmChangeEmailRequest) (*auth.ConfirmChangeEmailResponse, error)
+	DeleteAccount(ctx context.Context, request *auth.DeleteAccountRequest) (*auth.DeleteAccountResponse, error)
+	ExportMyData(ctx context.Context, request *auth.ExportMyDataRequest) (*auth.ExportMyDataResponse, error)
+	RequestPasswordReset(ctx context.Context, request *auth.RequestPasswordResetRequest) (*auth.RequestPasswordResetResponse, error)
+	ResetPassword(ctx context.Context, request *auth.ResetPasswordRequest) (*auth.ResetPasswordResponse, error)
+	LoginWithOidc(ctx context.Context, request *auth.LoginWithOidcRequest) (*auth.LoginWithOidcResponse, error)
+}
+
+// userDataExport adalah isi file ExportMyData. Baru berisi profil dan alamat karena
+// service order dan review belum ada di repo ini. Saat service tersebut dibuat,
+// tambahkan field orders dan reviews di sini agar export tetap lengkap.
+type userDataExport struct {
+	ExportedAt time.Time           `json:"exported_at"`
+	Profile    userProfileExport   `json:"profile"`
+	Addresses  []userAddressExport `json:"addresses"`
+}
+
+type userProfileExport struct {
+	Id          string            `json:"id"`
+	FullName    string            `json:"full_name"`
+	Email       string            `json:"email"`
+	PhoneNumber string            `json:"phone_number"`
+	AvatarUrl   string            `json:"avatar_url"`
+	Preferences map[string]string `json:"preferences"`
+	RoleCode    string            `json:"role_code"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+type userAddressExport struct {
+	entity.AddressSnapshot
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
 type authService struct {
-	authRepository repository.IAuthRepository
-	cacheService   *gocache.Cache
+	txManager         database.ITransactionManager
+	authRepository    repository.IAuthRepository
+	addressRepository repository.IAddressRepository
+	cacheService      *gocache.Cache
+	keyManager        *jwtentity.KeyManager
+	mailer            mailer.IMailer
+	passwordPolicy    *passwordpolicy.Policy
+	passwordHasher    passwordhash.IPasswordHasher
+	oidcVerifier      oidclogin.IVerifier
 }
 
 func (s *authService) Register(ctx context.Context, request *auth.RegisterRequest) (*auth.RegisterResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
 	if request.Password != request.PasswordConfirmation {
 		return &auth.RegisterResponse{
 			Base: utils.BadRequestResponse("Password and Confirm Password not match"),
 		}, nil
 	}
+	if violations := s.passwordPolicy.Validate("password", request.Password, request.Email, request.FullName); violations != nil {
+		return &auth.RegisterResponse{
+			Base: utils.ValidationErrorResponse(violations),
+		}, nil
+	}
 	user, err := s.authRepository.GetUserByEmail(ctx, request.Email)
 	if err != nil {
 		return nil, err
@@ -49,23 +108,25 @@ func (s *authService) Register(ctx context.Context, request *auth.RegisterReques
 		}, nil
 	}
 
-	bcryptPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwordHasher.Hash(request.Password)
 	if err != nil {
 		return nil, err
 	}
+	userId := uuid.NewString()
 	newUser := entity.User{
-		Id:        uuid.NewString(),
+		Id:        userId,
 		FullName:  request.FullName,
 		Email:     request.Email,
-		Password:  string(bcryptPassword),
+		Password:  hashedPassword,
 		RoleCode:  entity.UserRoleCustomer,
 		CreatedAt: time.Now(),
-		CreatedBy: request.FullName,
+		CreatedBy: userId,
 	}
 	err = s.authRepository.InsertUser(ctx, &newUser)
 	if err != nil {
 		return nil, err
 	}
+	metrics.UserRegistered(metrics.LoginMethodPassword)
 
 	return &auth.RegisterResponse{
 		Base: utils.SuccessResponse("User is Registered"),
@@ -73,39 +134,55 @@ func (s *authService) Register(ctx context.Context, request *auth.RegisterReques
 }
 
 func (s *authService) Login(ctx context.Context, request *auth.LoginRequest) (*auth.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
 	user, err := s.authRepository.GetUserByEmail(ctx, request.Email)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
+		metrics.LoginFailed(metrics.LoginMethodPassword, "user_not_found")
 		return &auth.LoginResponse{
 			Base: utils.BadRequestResponse("User is not registered"),
 		}, nil
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
+	match, err := s.passwordHasher.Verify(user.Password, request.Password)
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			return nil, status.Error(codes.Unauthenticated, "unauthenticated") // authentication from grpc
-		}
 		return nil, err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtentity.JwtClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "ecommerce-furniture",
-			Subject:   user.Id,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-		Email:    user.Email,
-		FullName: user.FullName,
-		Role:     user.RoleCode,
-	})
-	secretKey := os.Getenv("JWT_SECRET_KEY")
-	accessToken, err := token.SignedString([]byte(secretKey))
+	if !match {
+		metrics.LoginFailed(metrics.LoginMethodPassword, "invalid_password")
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated") // authentication from grpc
+	}
+	if user.IsDisabled {
+		metrics.LoginFailed(metrics.LoginMethodPassword, "user_disabled")
+		return &auth.LoginResponse{
+			Base: utils.BadRequestResponse("User is disabled"),
+		}, nil
+	}
+	if user.PasswordResetRequired {
+		metrics.LoginFailed(metrics.LoginMethodPassword, "password_reset_required")
+		return &auth.LoginResponse{
+			Base: utils.BadRequestResponse("Password reset required, please check your email"),
+		}, nil
+	}
+	// hash lama (algoritma atau cost berbeda) diganti selagi password asli tersedia
+	if s.passwordHasher.NeedsRehash(user.Password) {
+		hashedPassword, err := s.passwordHasher.Hash(request.Password)
+		if err == nil {
+			err = s.authRepository.UpdateUserPasswordHash(ctx, user.Id, hashedPassword)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to rehash password", "user_id", user.Id, "error", err)
+		}
+	}
+	accessToken, err := s.issueAccessToken(user)
 	if err != nil {
 		return nil, err
 	}
+	metrics.LoginSucceeded(metrics.LoginMethodPassword)
 
 	return &auth.LoginResponse{
 		Base:        utils.SuccessResponse("Login Success"),
@@ -114,6 +191,9 @@ func (s *authService) Login(ctx context.Context, request *auth.LoginRequest) (*a
 }
 
 func (s *authService) Logout(ctx context.Context, request *auth.LogoutRequest) (*auth.LogoutResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer span.End()
+
 	jwtToken, err := jwtentity.ParseTokenFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -122,13 +202,16 @@ func (s *authService) Logout(ctx context.Context, request *auth.LogoutRequest) (
 	if err != nil {
 		return nil, err
 	}
-	s.cacheService.Set(jwtToken, "", time.Duration(tokenClaims.ExpiresAt.Unix()-time.Now().Unix())*time.Second)
+	s.blacklistToken(jwtToken, tokenClaims)
 	return &auth.LogoutResponse{
 		Base: utils.SuccessResponse("Logout Success"),
 	}, nil
 }
 
 func (s *authService) ChangePassword(ctx context.Context, request *auth.ChangePasswordRequest) (*auth.ChangePasswordResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ChangePassword")
+	defer span.End()
+
 	if request.NewPassword != request.NewPasswordConfirmation {
 		return &auth.ChangePasswordResponse{
 			Base: utils.BadRequestResponse("New Password and Confirm Password not match"),
@@ -139,11 +222,11 @@ func (s *authService) ChangePassword(ctx context.Context, request *auth.ChangePa
 	if err != nil {
 		return nil, err
 	}
-	tokenClaims, err := jwtentity.GetClaimsFromToken(jwtToken)
+	tokenClaims, err := s.keyManager.GetClaimsFromToken(jwtToken)
 	if err != nil {
 		return nil, err
 	}
-	user, err := s.authRepository.GetUserByEmail(ctx, tokenClaims.Email)
+	user, err := s.authRepository.GetUserById(ctx, tokenClaims.Subject)
 	if err != nil {
 		return nil, err
 	}
@@ -152,20 +235,25 @@ func (s *authService) ChangePassword(ctx context.Context, request *auth.ChangePa
 			Base: utils.BadRequestResponse("User is not registered"),
 		}, nil
 	}
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.OldPassword))
+	match, err := s.passwordHasher.Verify(user.Password, request.OldPassword)
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			return nil, status.Error(codes.Unauthenticated, "unauthenticated") // authentication from grpc
-		}
 		return nil, err
 	}
+	if !match {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated") // authentication from grpc
+	}
+	if violations := s.passwordPolicy.Validate("new_password", request.NewPassword, user.Email, user.FullName); violations != nil {
+		return &auth.ChangePasswordResponse{
+			Base: utils.ValidationErrorResponse(violations),
+		}, nil
+	}
 
-	bcryptPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwordHasher.Hash(request.NewPassword)
 	if err != nil {
 		return nil, err
 	}
-	user.Password = string(bcryptPassword)
-	err = s.authRepository.UpdateUserPassword(ctx, user.Id, user.Password, tokenClaims.FullName)
+	user.Password = hashedPassword
+	err = s.authRepository.UpdateUserPassword(ctx, user.Id, user.Password, tokenClaims.Subject)
 	if err != nil {
 		return nil, err
 	}
@@ -176,11 +264,14 @@ func (s *authService) ChangePassword(ctx context.Context, request *auth.ChangePa
 }
 
 func (s *authService) GetProfile(ctx context.Context, request *auth.GetProfileRequest) (*auth.GetProfileResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetProfile")
+	defer span.End()
+
 	claims, err := jwtentity.GetClaimsFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	user, err := s.authRepository.GetUserByEmail(ctx, claims.Email)
+	user, err := s.authRepository.GetUserById(ctx, claims.Subject)
 	if err != nil {
 		return nil, err
 	}
@@ -192,17 +283,584 @@ func (s *authService) GetProfile(ctx context.Context, request *auth.GetProfileRe
 
 	return &auth.GetProfileResponse{
 		Base:        utils.SuccessResponse("Get Profile Success"),
-		UserId:      claims.Subject,
-		Email:       claims.Email,
-		FullName:    claims.FullName,
-		RoleCode:    claims.Role,
+		UserId:      user.Id,
+		Email:       user.Email,
+		FullName:    user.FullName,
+		RoleCode:    user.RoleCode,
 		MemberSince: timestamppb.New(user.CreatedAt),
+		PhoneNumber: user.PhoneNumber,
+		AvatarUrl:   user.AvatarUrl,
+		Preferences: user.Preferences,
+	}, nil
+}
+
+func (s *authService) GetJwks(ctx context.Context, request *auth.GetJwksRequest) (*auth.GetJwksResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetJwks")
+	defer span.End()
+
+	jwks := s.keyManager.Jwks()
+	keys := make([]*auth.Jwk, 0, len(jwks))
+	for _, jwk := range jwks {
+		keys = append(keys, &auth.Jwk{
+			Kty: jwk.Kty,
+			Kid: jwk.Kid,
+			Use: jwk.Use,
+			Alg: jwk.Alg,
+			N:   jwk.N,
+			E:   jwk.E,
+			Crv: jwk.Crv,
+			X:   jwk.X,
+		})
+	}
+
+	return &auth.GetJwksResponse{
+		Base: utils.SuccessResponse("Get Jwks Success"),
+		Keys: keys,
+	}, nil
+}
+
+func (s *authService) UpdateProfile(ctx context.Context, request *auth.UpdateProfileRequest) (*auth.UpdateProfileResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.UpdateProfile")
+	defer span.End()
+
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authRepository.GetUserById(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return &auth.UpdateProfileResponse{
+			Base: utils.BadRequestResponse("User is not registered"),
+		}, nil
+	}
+
+	user.FullName = request.FullName
+	user.PhoneNumber = request.PhoneNumber
+	user.AvatarUrl = request.AvatarUrl
+	user.Preferences = request.Preferences
+	user.UpdatedAt = time.Now()
+	user.UpdatedBy = claims.Subject
+	err = s.authRepository.UpdateUserProfile(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.UpdateProfileResponse{
+		Base: utils.SuccessResponse("Update Profile Success"),
 	}, nil
 }
 
-func NewAuthService(authRepository repository.IAuthRepository, cacheService *gocache.Cache) IAuthService {
+func (s *authService) ChangeEmail(ctx context.Context, request *auth.ChangeEmailRequest) (*auth.ChangeEmailResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ChangeEmail")
+	defer span.End()
+
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authRepository.GetUserById(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return &auth.ChangeEmailResponse{
+			Base: utils.BadRequestResponse("User is not registered"),
+		}, nil
+	}
+	match, err := s.passwordHasher.Verify(user.Password, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated") // authentication from grpc
+	}
+	if request.NewEmail == user.Email {
+		return &auth.ChangeEmailResponse{
+			Base: utils.BadRequestResponse("New Email is the same as current Email"),
+		}, nil
+	}
+	existingUser, err := s.authRepository.GetUserByEmail(ctx, request.NewEmail)
+	if err != nil {
+		return nil, err
+	}
+	if existingUser != nil {
+		return &auth.ChangeEmailResponse{
+			Base: utils.BadRequestResponse("Email already used"),
+		}, nil
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = s.authRepository.InsertPendingEmailChange(ctx, &entity.PendingEmailChange{
+		TokenHash: utils.HashToken(token),
+		UserId:    user.Id,
+		NewEmail:  request.NewEmail,
+		ExpiresAt: now.Add(entity.PendingEmailChangeTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// konfirmasi dikirim ke alamat baru, email baru dipakai setelah dikonfirmasi
+	err = s.mailer.Send(ctx, request.NewEmail, "Confirm your new email", fmt.Sprintf(
+		"Hi %s,\n\nUse this code to confirm your new email address: %s\n\nThe code expires in %s.",
+		user.FullName, token, entity.PendingEmailChangeTTL,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.ChangeEmailResponse{
+		Base: utils.SuccessResponse("Confirmation has been sent to the new Email"),
+	}, nil
+}
+
+func (s *authService) ConfirmChangeEmail(ctx context.Context, request *auth.ConfirmChangeEmailRequest) (*auth.ConfirmChangeEmailResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ConfirmChangeEmail")
+	defer span.End()
+
+	// token dihapus di transaksi yang sama dengan perubahan email, sehingga hanya bisa dipakai sekali
+	var response *auth.ConfirmChangeEmailResponse
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		pending, err := s.authRepository.ConsumePendingEmailChange(ctx, utils.HashToken(request.Token))
+		if err != nil {
+			return err
+		}
+		if pending == nil || time.Now().After(pending.ExpiresAt) {
+			response = &auth.ConfirmChangeEmailResponse{
+				Base: utils.BadRequestResponse("Token is invalid or expired"),
+			}
+			return nil
+		}
+		user, err := s.authRepository.GetUserById(ctx, pending.UserId)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			response = &auth.ConfirmChangeEmailResponse{
+				Base: utils.BadRequestResponse("User is not registered"),
+			}
+			return nil
+		}
+		existingUser, err := s.authRepository.GetUserByEmail(ctx, pending.NewEmail)
+		if err != nil {
+			return err
+		}
+		if existingUser != nil {
+			response = &auth.ConfirmChangeEmailResponse{
+				Base: utils.BadRequestResponse("Email already used"),
+			}
+			return nil
+		}
+		err = s.authRepository.UpdateUserEmail(ctx, user.Id, pending.NewEmail, user.Id)
+		if err != nil {
+			return err
+		}
+		response = &auth.ConfirmChangeEmailResponse{
+			Base: utils.SuccessResponse("Change Email Success"),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *authService) DeleteAccount(ctx context.Context, request *auth.DeleteAccountRequest) (*auth.DeleteAccountResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.DeleteAccount")
+	defer span.End()
+
+	jwtToken, err := jwtentity.ParseTokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authRepository.GetUserById(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return &auth.DeleteAccountResponse{
+			Base: utils.BadRequestResponse("User is not registered"),
+		}, nil
+	}
+	// wajib login ulang dengan password sebelum akun dihapus
+	match, err := s.passwordHasher.Verify(user.Password, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated") // authentication from grpc
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// deleted_by diisi id agar nama user tidak tersisa setelah dianonimkan
+		err := s.authRepository.SoftDeleteUser(ctx, user.Id, user.Id)
+		if err != nil {
+			return err
+		}
+		// sesi lain tetap ditolak walaupun akun dipulihkan admin
+		return s.authRepository.RevokeUserTokens(ctx, user.Id, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	jwtentity.ForgetUserState(s.cacheService, user.Id)
+	s.blacklistToken(jwtToken, claims)
+
+	return &auth.DeleteAccountResponse{
+		Base:            utils.SuccessResponse("Delete Account Success"),
+		AnonymizedAfter: timestamppb.New(time.Now().Add(entity.AccountDeletionGracePeriod)),
+	}, nil
+}
+
+func (s *authService) ExportMyData(ctx context.Context, request *auth.ExportMyDataRequest) (*auth.ExportMyDataResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ExportMyData")
+	defer span.End()
+
+	claims, err := jwtentity.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authRepository.GetUserById(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return &auth.ExportMyDataResponse{
+			Base: utils.BadRequestResponse("User is not registered"),
+		}, nil
+	}
+	addresses, err := s.addressRepository.ListAddressesByUserId(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	export := userDataExport{
+		ExportedAt: time.Now(),
+		Profile: userProfileExport{
+			Id:          user.Id,
+			FullName:    user.FullName,
+			Email:       user.Email,
+			PhoneNumber: user.PhoneNumber,
+			AvatarUrl:   user.AvatarUrl,
+			Preferences: user.Preferences,
+			RoleCode:    user.RoleCode,
+			CreatedAt:   user.CreatedAt,
+		},
+		Addresses: make([]userAddressExport, 0, len(addresses)),
+	}
+	for _, address := range addresses {
+		export.Addresses = append(export.Addresses, userAddressExport{
+			AddressSnapshot:   address.Snapshot(),
+			IsDefaultShipping: address.IsDefaultShipping,
+			IsDefaultBilling:  address.IsDefaultBilling,
+			CreatedAt:         address.CreatedAt,
+		})
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.ExportMyDataResponse{
+		Base:        utils.SuccessResponse("Export Data Success"),
+		FileName:    fmt.Sprintf("my-data-%s.json", export.ExportedAt.Format("20060102150405")),
+		ContentType: "application/json",
+		Data:        data,
+	}, nil
+}
+
+func (s *authService) RequestPasswordReset(ctx context.Context, request *auth.RequestPasswordResetRequest) (*auth.RequestPasswordResetResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RequestPasswordReset")
+	defer span.End()
+
+	// respons selalu sukses agar endpoint tidak bisa dipakai untuk mengecek email yang terdaftar
+	response := &auth.RequestPasswordResetResponse{
+		Base: utils.SuccessResponse("If the Email is registered, a reset code has been sent"),
+	}
+	user, err := s.authRepository.GetUserByEmail(ctx, request.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDisabled {
+		return response, nil
+	}
+	err = sendPasswordResetToken(ctx, s.authRepository, s.mailer, user)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to send password reset token", "user_id", user.Id, "error", err)
+	}
+	return response, nil
+}
+
+func (s *authService) ResetPassword(ctx context.Context, request *auth.ResetPasswordRequest) (*auth.ResetPasswordResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ResetPassword")
+	defer span.End()
+
+	if request.NewPassword != request.NewPasswordConfirmation {
+		return &auth.ResetPasswordResponse{
+			Base: utils.BadRequestResponse("New Password and Confirm Password not match"),
+		}, nil
+	}
+	invalidToken := &auth.ResetPasswordResponse{
+		Base: utils.BadRequestResponse("Token is invalid or expired"),
+	}
+	tokenHash := utils.HashToken(request.Token)
+	// token belum dihapus di sini, password yang ditolak policy tidak menghanguskan token
+	resetToken, err := s.authRepository.GetPasswordResetToken(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if resetToken == nil || time.Now().After(resetToken.ExpiresAt) {
+		return invalidToken, nil
+	}
+	user, err := s.authRepository.GetUserById(ctx, resetToken.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return &auth.ResetPasswordResponse{
+			Base: utils.BadRequestResponse("User is not registered"),
+		}, nil
+	}
+	if violations := s.passwordPolicy.Validate("new_password", request.NewPassword, user.Email, user.FullName); violations != nil {
+		return &auth.ResetPasswordResponse{
+			Base: utils.ValidationErrorResponse(violations),
+		}, nil
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(request.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	// token dihapus di transaksi yang sama dengan perubahan password, sehingga hanya bisa dipakai sekali
+	consumed := false
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		resetToken, err := s.authRepository.ConsumePasswordResetToken(ctx, tokenHash)
+		if err != nil {
+			return err
+		}
+		if resetToken == nil || time.Now().After(resetToken.ExpiresAt) {
+			return nil
+		}
+		err = s.authRepository.UpdateUserPassword(ctx, user.Id, hashedPassword, user.Id)
+		if err != nil {
+			return err
+		}
+		consumed = true
+		return s.authRepository.RevokeUserTokens(ctx, user.Id, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return invalidToken, nil
+	}
+	jwtentity.ForgetUserState(s.cacheService, user.Id)
+
+	return &auth.ResetPasswordResponse{
+		Base: utils.SuccessResponse("Reset Password Success"),
+	}, nil
+}
+
+func (s *authService) LoginWithOidc(ctx context.Context, request *auth.LoginWithOidcRequest) (*auth.LoginWithOidcResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.LoginWithOidc")
+	defer span.End()
+
+	if (request.IdToken == "") == (request.Code == "") {
+		return &auth.LoginWithOidcResponse{
+			Base: utils.BadRequestResponse("Either id_token or code is required"),
+		}, nil
+	}
+	identity, err := s.oidcVerifier.Verify(ctx, request.Provider, oidclogin.Credential{
+		IdToken:      request.IdToken,
+		Code:         request.Code,
+		CodeVerifier: request.CodeVerifier,
+		Nonce:        request.Nonce,
+	})
+	if err != nil {
+		if errors.Is(err, oidclogin.ErrUnknownProvider) {
+			return &auth.LoginWithOidcResponse{
+				Base: utils.BadRequestResponse("Provider is not supported"),
+			}, nil
+		}
+		if errors.Is(err, oidclogin.ErrEmailNotVerified) {
+			metrics.LoginFailed(metrics.LoginMethodOidc, "email_not_verified")
+			return &auth.LoginWithOidcResponse{
+				Base: utils.BadRequestResponse("Email is not verified by the provider"),
+			}, nil
+		}
+		logger.FromContext(ctx).Warn("oidc login failed", "provider", request.Provider, "error", err)
+		metrics.LoginFailed(metrics.LoginMethodOidc, "invalid_token")
+		return nil, utils.UnauthenticatedResponse()
+	}
+
+	user, isNewUser, err := s.findOrCreateOidcUser(ctx, identity)
+	if err != nil {
+		if errors.Is(err, errOidcLinkNotAllowed) {
+			metrics.LoginFailed(metrics.LoginMethodOidc, "link_not_allowed")
+			return &auth.LoginWithOidcResponse{
+				Base: utils.BadRequestResponse("This account cannot be linked automatically, please sign in with password"),
+			}, nil
+		}
+		return nil, err
+	}
+	if isNewUser {
+		metrics.UserRegistered(metrics.LoginMethodOidc)
+	}
+	// pemeriksaan status akun sama dengan Login
+	if user.IsDisabled {
+		metrics.LoginFailed(metrics.LoginMethodOidc, "user_disabled")
+		return &auth.LoginWithOidcResponse{
+			Base: utils.BadRequestResponse("User is disabled"),
+		}, nil
+	}
+	if user.PasswordResetRequired {
+		metrics.LoginFailed(metrics.LoginMethodOidc, "password_reset_required")
+		return &auth.LoginWithOidcResponse{
+			Base: utils.BadRequestResponse("Password reset required, please check your email"),
+		}, nil
+	}
+	accessToken, err := s.issueAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+	metrics.LoginSucceeded(metrics.LoginMethodOidc)
+
+	return &auth.LoginWithOidcResponse{
+		Base:        utils.SuccessResponse("Login Success"),
+		AccessToken: accessToken,
+		IsNewUser:   isNewUser,
+	}, nil
+}
+
+// errOidcLinkNotAllowed dikembalikan jika email identity milik user selain customer
+var errOidcLinkNotAllowed = errors.New("oidc identity cannot be linked to this user automatically")
+
+// findOrCreateOidcUser mencari user dari identity yang sudah terhubung, lalu dari email
+// yang sudah diverifikasi provider, dan membuat user baru bila belum ada. Identity
+// milik user yang sudah dihapus dipindahkan ke user tersebut. Hanya customer yang
+// dihubungkan otomatis lewat email, akun admin dan service account harus dihubungkan
+// secara eksplisit agar penguasaan email di provider tidak cukup untuk mengambil alih.
+func (s *authService) findOrCreateOidcUser(ctx context.Context, identity *oidclogin.Identity) (*entity.User, bool, error) {
+	userIdentity, err := s.authRepository.GetUserIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, false, err
+	}
+	if userIdentity != nil {
+		user, err := s.authRepository.GetUserById(ctx, userIdentity.UserId)
+		if err != nil {
+			return nil, false, err
+		}
+		if user != nil {
+			return user, false, nil
+		}
+	}
+
+	user, err := s.authRepository.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, false, err
+	}
+	if user != nil && user.RoleCode != entity.UserRoleCustomer {
+		return nil, false, errOidcLinkNotAllowed
+	}
+	// akun yang diblokir tidak dihubungkan, login ditolak di LoginWithOidc
+	if user != nil && (user.IsDisabled || user.PasswordResetRequired) {
+		return user, false, nil
+	}
+	isNewUser := user == nil
+	if isNewUser {
+		fullName := identity.Name
+		if fullName == "" {
+			fullName = identity.Email
+		}
+		// user OIDC tidak punya password, login dengan password selalu gagal
+		userId := uuid.NewString()
+		user = &entity.User{
+			Id:        userId,
+			FullName:  fullName,
+			Email:     identity.Email,
+			AvatarUrl: identity.Picture,
+			RoleCode:  entity.UserRoleCustomer,
+			CreatedAt: time.Now(),
+			CreatedBy: userId,
+		}
+	}
+
+	// user baru dan identity-nya disimpan bersama agar tidak ada user tanpa identity
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if isNewUser {
+			err := s.authRepository.InsertUser(ctx, user)
+			if err != nil {
+				return err
+			}
+		}
+		if userIdentity != nil {
+			return s.authRepository.RelinkUserIdentity(ctx, userIdentity.Id, user.Id, identity.Email)
+		}
+		return s.authRepository.InsertUserIdentity(ctx, &entity.UserIdentity{
+			Id:        uuid.NewString(),
+			UserId:    user.Id,
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return user, isNewUser, nil
+}
+
+func (s *authService) issueAccessToken(user *entity.User) (string, error) {
+	return s.keyManager.SignClaims(jwtentity.JwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "ecommerce-furniture",
+			Subject:   user.Id,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(jwtentity.AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		Email:    user.Email,
+		FullName: user.FullName,
+		Role:     user.RoleCode,
+	})
+}
+
+// blacklistToken menolak token sampai masa berlakunya habis
+func (s *authService) blacklistToken(token string, claims *jwtentity.JwtClaims) {
+	ttl := jwtentity.AccessTokenTTL
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+	// durasi <= 0 di go-cache berarti tidak pernah kedaluwarsa
+	if ttl <= 0 {
+		return
+	}
+	s.cacheService.Set(token, "", ttl)
+}
+
+func NewAuthService(txManager database.ITransactionManager, authRepository repository.IAuthRepository, addressRepository repository.IAddressRepository, cacheService *gocache.Cache, keyManager *jwtentity.KeyManager, mailer mailer.IMailer, passwordPolicy *passwordpolicy.Policy, passwordHasher passwordhash.IPasswordHasher, oidcVerifier oidclogin.IVerifier) IAuthService {
 	return &authService{
-		authRepository: authRepository,
-		cacheService:   cacheService,
+		txManager:         txManager,
+		authRepository:    authRepository,
+		addressRepository: addressRepository,
+		cacheService:      cacheService,
+		keyManager:        keyManager,
+		mailer:            mailer,
+		passwordPolicy:    passwordPolicy,
+		passwordHasher:    passwordHasher,
+		oidcVerifier:      oidcVerifier,
 	}
 }
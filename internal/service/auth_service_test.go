@@ -0,0 +1,610 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/oidclogin"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/passwordhash"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/passwordpolicy"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/auth"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeAuthRepository menyimpan user dan identity di memory dengan aturan unik
+// yang sama seperti tabel aslinya. Method yang tidak diimplementasikan panic.
+type fakeAuthRepository struct {
+	repository.IAuthRepository
+	users        map[string]*entity.User
+	identities   map[string]*entity.UserIdentity
+	emailChanges map[string]*entity.PendingEmailChange
+	resetTokens  map[string]*entity.PasswordResetToken
+}
+
+func newFakeAuthRepository() *fakeAuthRepository {
+	return &fakeAuthRepository{
+		users:        make(map[string]*entity.User),
+		identities:   make(map[string]*entity.UserIdentity),
+		emailChanges: make(map[string]*entity.PendingEmailChange),
+		resetTokens:  make(map[string]*entity.PasswordResetToken),
+	}
+}
+
+func (r *fakeAuthRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.Email == email && !user.IsDeleted {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeAuthRepository) GetUserById(ctx context.Context, userId string) (*entity.User, error) {
+	user, ok := r.users[userId]
+	if !ok || user.IsDeleted {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (r *fakeAuthRepository) InsertUser(ctx context.Context, user *entity.User) error {
+	r.users[user.Id] = user
+	return nil
+}
+
+func (r *fakeAuthRepository) GetUserIdentity(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error) {
+	return r.identities[provider+"|"+subject], nil
+}
+
+func (r *fakeAuthRepository) InsertUserIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	key := identity.Provider + "|" + identity.Subject
+	if _, ok := r.identities[key]; ok {
+		return fmt.Errorf("duplicate key value violates unique constraint on user_identity (provider, subject)")
+	}
+	r.identities[key] = identity
+	return nil
+}
+
+func (r *fakeAuthRepository) RelinkUserIdentity(ctx context.Context, identityId string, userId string, email string) error {
+	for _, identity := range r.identities {
+		if identity.Id == identityId {
+			identity.UserId = userId
+			identity.Email = email
+		}
+	}
+	return nil
+}
+
+func (r *fakeAuthRepository) UpdateUserEmail(ctx context.Context, userId string, email string, updatedBy string) error {
+	r.users[userId].Email = email
+	r.users[userId].UpdatedBy = updatedBy
+	return nil
+}
+
+func (r *fakeAuthRepository) InsertPendingEmailChange(ctx context.Context, change *entity.PendingEmailChange) error {
+	for tokenHash, existing := range r.emailChanges {
+		if existing.UserId == change.UserId {
+			delete(r.emailChanges, tokenHash)
+		}
+	}
+	r.emailChanges[change.TokenHash] = change
+	return nil
+}
+
+func (r *fakeAuthRepository) ConsumePendingEmailChange(ctx context.Context, tokenHash string) (*entity.PendingEmailChange, error) {
+	change := r.emailChanges[tokenHash]
+	delete(r.emailChanges, tokenHash)
+	return change, nil
+}
+
+func (r *fakeAuthRepository) InsertPasswordResetToken(ctx context.Context, token *entity.PasswordResetToken) error {
+	for tokenHash, existing := range r.resetTokens {
+		if existing.UserId == token.UserId {
+			delete(r.resetTokens, tokenHash)
+		}
+	}
+	r.resetTokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakeAuthRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	return r.resetTokens[tokenHash], nil
+}
+
+func (r *fakeAuthRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	token := r.resetTokens[tokenHash]
+	delete(r.resetTokens, tokenHash)
+	return token, nil
+}
+
+func (r *fakeAuthRepository) UpdateUserPassword(ctx context.Context, userId string, hashedNewPassword string, updatedBy string) error {
+	r.users[userId].Password = hashedNewPassword
+	r.users[userId].PasswordResetRequired = false
+	r.users[userId].UpdatedBy = updatedBy
+	return nil
+}
+
+func (r *fakeAuthRepository) RevokeUserTokens(ctx context.Context, userId string, validAfter time.Time) error {
+	r.users[userId].TokensValidAfter = validAfter
+	return nil
+}
+
+type fakeTransactionManager struct{}
+
+func (fakeTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...database.TxOption) error {
+	return fn(ctx)
+}
+
+type fakeOidcVerifier struct {
+	identity *oidclogin.Identity
+}
+
+func (v *fakeOidcVerifier) Verify(ctx context.Context, providerName string, credential oidclogin.Credential) (*oidclogin.Identity, error) {
+	return v.identity, nil
+}
+
+// fakeMailer menyimpan email terakhir, token diambil dari isi email
+type fakeMailer struct {
+	to   string
+	body string
+}
+
+func (m *fakeMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	m.to, m.body = to, body
+	return nil
+}
+
+var tokenPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+func (m *fakeMailer) token(t *testing.T) string {
+	t.Helper()
+	token := tokenPattern.FindString(m.body)
+	if token == "" {
+		t.Fatalf("no token in email body %q", m.body)
+	}
+	return token
+}
+
+func newTestPasswordHasher(t *testing.T) passwordhash.IPasswordHasher {
+	t.Helper()
+	hasher, err := passwordhash.NewPasswordHasher(passwordhash.Config{Algorithm: passwordhash.AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hasher
+}
+
+func newTestKeyManager(t *testing.T) *jwtentity.KeyManager {
+	t.Helper()
+	keyManager, err := jwtentity.NewKeyManager(jwtentity.KeyManagerConfig{
+		Algorithm:        jwtentity.AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+		Overlap:          time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyManager
+}
+
+func TestLoginWithOidc(t *testing.T) {
+	identity := &oidclogin.Identity{Provider: "google", Subject: "google-sub-1", Email: "budi@example.com", Name: "Budi"}
+
+	tests := []struct {
+		name string
+		// menyiapkan data awal di repository
+		setup         func(repo *fakeAuthRepository)
+		wantStatus    int64
+		wantNewUser   bool
+		wantLinkedTo  string
+		wantUserCount int
+		// identity tidak boleh tersimpan
+		wantNotLinked bool
+	}{
+		{
+			name:          "new user",
+			setup:         func(repo *fakeAuthRepository) {},
+			wantStatus:    200,
+			wantNewUser:   true,
+			wantUserCount: 1,
+		},
+		{
+			name: "linked identity",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleCustomer}
+				repo.identities["google|google-sub-1"] = &entity.UserIdentity{Id: "identity-1", UserId: "user-1", Provider: "google", Subject: "google-sub-1"}
+			},
+			wantStatus:    200,
+			wantLinkedTo:  "user-1",
+			wantUserCount: 1,
+		},
+		{
+			name: "existing user with same email",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleCustomer}
+			},
+			wantStatus:    200,
+			wantLinkedTo:  "user-1",
+			wantUserCount: 1,
+		},
+		{
+			name: "identity of soft deleted user is relinked to new user",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleCustomer, IsDeleted: true}
+				repo.identities["google|google-sub-1"] = &entity.UserIdentity{Id: "identity-1", UserId: "user-1", Provider: "google", Subject: "google-sub-1"}
+			},
+			wantStatus:    200,
+			wantNewUser:   true,
+			wantUserCount: 2,
+		},
+		{
+			name: "identity of soft deleted user is relinked to active user with same email",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleCustomer, IsDeleted: true}
+				repo.users["user-2"] = &entity.User{Id: "user-2", Email: identity.Email, RoleCode: entity.UserRoleCustomer}
+				repo.identities["google|google-sub-1"] = &entity.UserIdentity{Id: "identity-1", UserId: "user-1", Provider: "google", Subject: "google-sub-1"}
+			},
+			wantStatus:    200,
+			wantLinkedTo:  "user-2",
+			wantUserCount: 2,
+		},
+		{
+			name: "disabled user",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleCustomer, IsDisabled: true}
+				repo.identities["google|google-sub-1"] = &entity.UserIdentity{Id: "identity-1", UserId: "user-1", Provider: "google", Subject: "google-sub-1"}
+			},
+			wantStatus:    400,
+			wantLinkedTo:  "user-1",
+			wantUserCount: 1,
+		},
+		{
+			name: "linked user requires password reset",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleCustomer, PasswordResetRequired: true}
+				repo.identities["google|google-sub-1"] = &entity.UserIdentity{Id: "identity-1", UserId: "user-1", Provider: "google", Subject: "google-sub-1"}
+			},
+			wantStatus:    400,
+			wantLinkedTo:  "user-1",
+			wantUserCount: 1,
+		},
+		{
+			name: "disabled user with same email is not linked",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleCustomer, IsDisabled: true}
+			},
+			wantStatus:    400,
+			wantUserCount: 1,
+			wantNotLinked: true,
+		},
+		{
+			name: "admin with same email is not linked",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleAdmin}
+			},
+			wantStatus:    400,
+			wantUserCount: 1,
+			wantNotLinked: true,
+		},
+		{
+			name: "service account with same email is not linked",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleServiceAccount}
+			},
+			wantStatus:    400,
+			wantUserCount: 1,
+			wantNotLinked: true,
+		},
+		{
+			// identity yang sudah dihubungkan secara eksplisit tetap bisa dipakai admin
+			name: "admin with linked identity",
+			setup: func(repo *fakeAuthRepository) {
+				repo.users["user-1"] = &entity.User{Id: "user-1", Email: identity.Email, RoleCode: entity.UserRoleAdmin}
+				repo.identities["google|google-sub-1"] = &entity.UserIdentity{Id: "identity-1", UserId: "user-1", Provider: "google", Subject: "google-sub-1"}
+			},
+			wantStatus:    200,
+			wantLinkedTo:  "user-1",
+			wantUserCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAuthRepository()
+			tt.setup(repo)
+			svc := &authService{
+				txManager:      fakeTransactionManager{},
+				authRepository: repo,
+				keyManager:     newTestKeyManager(t),
+				oidcVerifier:   &fakeOidcVerifier{identity: identity},
+			}
+
+			res, err := svc.LoginWithOidc(context.Background(), &auth.LoginWithOidcRequest{Provider: "google", IdToken: "token"})
+			if err != nil {
+				t.Fatalf("LoginWithOidc() error = %v", err)
+			}
+			if res.Base.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", res.Base.StatusCode, res.Base.Message, tt.wantStatus)
+			}
+			if res.IsNewUser != tt.wantNewUser {
+				t.Errorf("IsNewUser = %v, want %v", res.IsNewUser, tt.wantNewUser)
+			}
+			if tt.wantStatus == 200 && res.AccessToken == "" {
+				t.Error("expected access token")
+			}
+			if len(repo.users) != tt.wantUserCount {
+				t.Errorf("user count = %d, want %d", len(repo.users), tt.wantUserCount)
+			}
+
+			linked := repo.identities["google|google-sub-1"]
+			if tt.wantNotLinked {
+				if linked != nil {
+					t.Errorf("identity linked to %q, want not linked", linked.UserId)
+				}
+				return
+			}
+			if linked == nil {
+				t.Fatal("identity was not stored")
+			}
+			user, _ := repo.GetUserById(context.Background(), linked.UserId)
+			if user == nil {
+				t.Fatalf("identity linked to missing or deleted user %q", linked.UserId)
+			}
+			if tt.wantLinkedTo != "" && linked.UserId != tt.wantLinkedTo {
+				t.Errorf("identity linked to %q, want %q", linked.UserId, tt.wantLinkedTo)
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresAt *jwt.NumericDate
+		wantCache bool
+	}{
+		{name: "token with expiry", expiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)), wantCache: true},
+		{name: "claims without expiry", expiresAt: nil, wantCache: true},
+		{name: "already expired", expiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)), wantCache: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheService := gocache.New(time.Minute, time.Minute)
+			svc := &authService{cacheService: cacheService}
+			claims := &jwtentity.JwtClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: tt.expiresAt}}
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer token-1"))
+			ctx = claims.SetToContext(ctx)
+
+			res, err := svc.Logout(ctx, &auth.LogoutRequest{})
+			if err != nil {
+				t.Fatalf("Logout() error = %v", err)
+			}
+			if res.Base.StatusCode != 200 {
+				t.Fatalf("status = %d", res.Base.StatusCode)
+			}
+			if _, ok := cacheService.Get("token-1"); ok != tt.wantCache {
+				t.Errorf("token blacklisted = %v, want %v", ok, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestChangeEmail(t *testing.T) {
+	hasher := newTestPasswordHasher(t)
+	hashedPassword, err := hasher.Hash("Kursi#Jati9")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// mengubah data sebelum konfirmasi
+		before     func(repo *fakeAuthRepository)
+		wantStatus int64
+		wantEmail  string
+	}{
+		{name: "confirmed", before: func(repo *fakeAuthRepository) {}, wantStatus: 200, wantEmail: "budi.baru@example.com"},
+		{
+			name: "expired",
+			before: func(repo *fakeAuthRepository) {
+				for _, change := range repo.emailChanges {
+					change.ExpiresAt = time.Now().Add(-time.Second)
+				}
+			},
+			wantStatus: 400,
+			wantEmail:  "budi@example.com",
+		},
+		{
+			name: "email taken before confirmation",
+			before: func(repo *fakeAuthRepository) {
+				repo.users["user-2"] = &entity.User{Id: "user-2", Email: "budi.baru@example.com"}
+			},
+			wantStatus: 400,
+			wantEmail:  "budi@example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAuthRepository()
+			repo.users["user-1"] = &entity.User{Id: "user-1", Email: "budi@example.com", FullName: "Budi", Password: hashedPassword}
+			mailer := &fakeMailer{}
+			newService := func() *authService {
+				return &authService{
+					txManager:      fakeTransactionManager{},
+					authRepository: repo,
+					cacheService:   gocache.New(time.Minute, time.Minute),
+					mailer:         mailer,
+					passwordHasher: hasher,
+				}
+			}
+			claims := &jwtentity.JwtClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"}, FullName: "Budi"}
+
+			res, err := newService().ChangeEmail(claims.SetToContext(context.Background()), &auth.ChangeEmailRequest{
+				NewEmail: "budi.baru@example.com",
+				Password: "Kursi#Jati9",
+			})
+			if err != nil {
+				t.Fatalf("ChangeEmail() error = %v", err)
+			}
+			if res.Base.StatusCode != 200 || mailer.to != "budi.baru@example.com" {
+				t.Fatalf("ChangeEmail() status = %d, mail sent to %q", res.Base.StatusCode, mailer.to)
+			}
+			token := mailer.token(t)
+			if _, ok := repo.emailChanges[token]; ok {
+				t.Error("pending email change stored with plain token, want hash")
+			}
+			tt.before(repo)
+
+			// konfirmasi bisa diterima replica lain yang tidak punya cache yang sama
+			confirm, err := newService().ConfirmChangeEmail(context.Background(), &auth.ConfirmChangeEmailRequest{Token: token})
+			if err != nil {
+				t.Fatalf("ConfirmChangeEmail() error = %v", err)
+			}
+			if confirm.Base.StatusCode != tt.wantStatus {
+				t.Errorf("ConfirmChangeEmail() status = %d, want %d", confirm.Base.StatusCode, tt.wantStatus)
+			}
+			if got := repo.users["user-1"].Email; got != tt.wantEmail {
+				t.Errorf("email = %q, want %q", got, tt.wantEmail)
+			}
+			if tt.wantStatus == 200 && repo.users["user-1"].UpdatedBy != "user-1" {
+				t.Errorf("updated_by = %q, want user id", repo.users["user-1"].UpdatedBy)
+			}
+
+			again, err := newService().ConfirmChangeEmail(context.Background(), &auth.ConfirmChangeEmailRequest{Token: token})
+			if err != nil {
+				t.Fatalf("ConfirmChangeEmail() error = %v", err)
+			}
+			if again.Base.StatusCode != 400 {
+				t.Errorf("second ConfirmChangeEmail() status = %d, want 400 (single use)", again.Base.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		disabled bool
+		wantMail bool
+	}{
+		{name: "registered", email: "budi@example.com", wantMail: true},
+		// respons sama agar email yang terdaftar tidak bisa ditebak
+		{name: "not registered", email: "siapa@example.com"},
+		{name: "disabled", email: "budi@example.com", disabled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAuthRepository()
+			repo.users["user-1"] = &entity.User{Id: "user-1", Email: "budi@example.com", FullName: "Budi", IsDisabled: tt.disabled}
+			mailer := &fakeMailer{}
+			service := &authService{authRepository: repo, mailer: mailer}
+
+			res, err := service.RequestPasswordReset(context.Background(), &auth.RequestPasswordResetRequest{Email: tt.email})
+			if err != nil {
+				t.Fatalf("RequestPasswordReset() error = %v", err)
+			}
+			if res.Base.StatusCode != 200 {
+				t.Errorf("RequestPasswordReset() status = %d, want 200", res.Base.StatusCode)
+			}
+			if sent := mailer.to != ""; sent != tt.wantMail {
+				t.Errorf("mail sent = %v, want %v", sent, tt.wantMail)
+			}
+			if tt.wantMail {
+				if _, ok := repo.resetTokens[mailer.token(t)]; ok {
+					t.Error("reset token stored in plain text, want hash")
+				}
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	hasher := newTestPasswordHasher(t)
+	tests := []struct {
+		name string
+		// mengubah data sebelum token ditukar
+		before      func(repo *fakeAuthRepository)
+		newPassword string
+		wantStatus  int64
+		// token masih bisa dipakai setelah permintaan ini
+		wantTokenKept bool
+	}{
+		{name: "reset", before: func(repo *fakeAuthRepository) {}, newPassword: "Kursi#Jati9", wantStatus: 200},
+		{
+			name: "expired",
+			before: func(repo *fakeAuthRepository) {
+				for _, token := range repo.resetTokens {
+					token.ExpiresAt = time.Now().Add(-time.Second)
+				}
+			},
+			newPassword: "Kursi#Jati9",
+			wantStatus:  400,
+			// token kedaluwarsa tidak dihapus, dibersihkan bersama data user
+			wantTokenKept: true,
+		},
+		{name: "password rejected by policy", before: func(repo *fakeAuthRepository) {}, newPassword: "pendek", wantStatus: 400, wantTokenKept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAuthRepository()
+			repo.users["user-1"] = &entity.User{Id: "user-1", Email: "budi@example.com", FullName: "Budi", PasswordResetRequired: true}
+			mailer := &fakeMailer{}
+			if err := sendPasswordResetToken(context.Background(), repo, mailer, repo.users["user-1"]); err != nil {
+				t.Fatalf("sendPasswordResetToken() error = %v", err)
+			}
+			token := mailer.token(t)
+			tt.before(repo)
+
+			// token bisa ditukar di replica lain yang tidak punya cache yang sama
+			newService := func() *authService {
+				return &authService{
+					txManager:      fakeTransactionManager{},
+					authRepository: repo,
+					cacheService:   gocache.New(time.Minute, time.Minute),
+					passwordPolicy: &passwordpolicy.Policy{MinLength: 8},
+					passwordHasher: hasher,
+				}
+			}
+			request := &auth.ResetPasswordRequest{Token: token, NewPassword: tt.newPassword, NewPasswordConfirmation: tt.newPassword}
+			res, err := newService().ResetPassword(context.Background(), request)
+			if err != nil {
+				t.Fatalf("ResetPassword() error = %v", err)
+			}
+			if res.Base.StatusCode != tt.wantStatus {
+				t.Errorf("ResetPassword() status = %d, want %d", res.Base.StatusCode, tt.wantStatus)
+			}
+			if _, kept := repo.resetTokens[utils.HashToken(token)]; kept != tt.wantTokenKept {
+				t.Errorf("token kept = %v, want %v", kept, tt.wantTokenKept)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			user := repo.users["user-1"]
+			if match, _ := hasher.Verify(user.Password, tt.newPassword); !match || user.PasswordResetRequired || user.TokensValidAfter.IsZero() {
+				t.Errorf("user after reset = %+v, want new password, reset flag cleared and tokens revoked", user)
+			}
+			if user.UpdatedBy != "user-1" {
+				t.Errorf("updated_by = %q, want user id", user.UpdatedBy)
+			}
+
+			again, err := newService().ResetPassword(context.Background(), request)
+			if err != nil {
+				t.Fatalf("ResetPassword() error = %v", err)
+			}
+			if again.Base.StatusCode != 400 {
+				t.Errorf("second ResetPassword() status = %d, want 400 (single use)", again.Base.StatusCode)
+			}
+		})
+	}
+}
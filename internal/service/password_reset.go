@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/mailer"
+)
+
+// sendPasswordResetToken membuat token reset password dan mengirimkannya ke email user.
+// Token ditukar lewat AuthService.ResetPassword. Token lama user otomatis dibatalkan.
+func sendPasswordResetToken(ctx context.Context, authRepository repository.IAuthRepository, mailService mailer.IMailer, user *entity.User) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	err = authRepository.InsertPasswordResetToken(ctx, &entity.PasswordResetToken{
+		TokenHash: utils.HashToken(token),
+		UserId:    user.Id,
+		ExpiresAt: now.Add(entity.PasswordResetTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	// jika email gagal dikirim token tetap tersimpan sampai kedaluwarsa, tapi tidak ada yang mengetahuinya
+	return mailService.Send(ctx, user.Email, "Reset your password", fmt.Sprintf(
+		"Hi %s,\n\nUse this code to set a new password: %s\n\nThe code expires in %s.",
+		user.FullName, token, entity.PasswordResetTokenTTL,
+	))
+}
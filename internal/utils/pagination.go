@@ -0,0 +1,33 @@
+package utils
+
+import "github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/common"
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// NormalizePagination mengisi nilai default page dan page size
+func NormalizePagination(request *common.PaginationRequest) (page int32, pageSize int32) {
+	page, pageSize = request.GetPage(), request.GetPageSize()
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+func PaginationResponse(page int32, pageSize int32, totalItems int64) *common.PaginationResponse {
+	totalPages := int32((totalItems + int64(pageSize) - 1) / int64(pageSize))
+	return &common.PaginationResponse{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const RequestIdMetadataKey = "x-request-id"
+
+// request id dari klien dibatasi agar tidak bisa mengotori log
+const maxRequestIdLength = 128
+
+type requestIdKey struct{}
+
+func NewRequestId() string {
+	return uuid.NewString()
+}
+
+func ValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, c := range requestId {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestIdFromContext mengembalikan request id yang disimpan interceptor,
+// atau header x-request-id dari metadata request
+func RequestIdFromContext(ctx context.Context) string {
+	if requestId, ok := ctx.Value(requestIdKey{}).(string); ok {
+		return requestId
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(RequestIdMetadataKey); len(values) > 0 && ValidRequestId(values[0]) {
+		return values[0]
+	}
+	return ""
+}
+
+type clientIpKey struct{}
+
+func WithClientIp(ctx context.Context, clientIp string) context.Context {
+	return context.WithValue(ctx, clientIpKey{}, clientIp)
+}
+
+// ClientIpFromContext mengembalikan IP yang ditentukan interceptor lewat
+// ResolveClientIp, atau alamat peer jika belum ada. x-forwarded-for tidak
+// dibaca langsung karena nilainya bisa diisi bebas oleh klien.
+func ClientIpFromContext(ctx context.Context) string {
+	if clientIp, ok := ctx.Value(clientIpKey{}).(string); ok {
+		return clientIp
+	}
+	return peerIpFromContext(ctx)
+}
+
+// ResolveClientIp membaca x-forwarded-for dari kanan dan melewati entri yang
+// ditambahkan proxy tepercaya. hops adalah jumlah proxy di depan server,
+// termasuk gateway REST jika request datang lewat gateway. Entri di sebelah
+// kiri entri proxy tepercaya dikirim klien sendiri sehingga tidak dipakai.
+func ResolveClientIp(ctx context.Context, hops int) string {
+	peerIp := peerIpFromContext(ctx)
+	if hops == 0 {
+		return peerIp
+	}
+
+	// urutan alamat dari klien terjauh sampai peer, tiap proxy menambahkan satu entri
+	chain := make([]string, 0)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("x-forwarded-for") {
+			for _, entry := range strings.Split(value, ",") {
+				if entry = strings.TrimSpace(entry); entry != "" {
+					chain = append(chain, entry)
+				}
+			}
+		}
+	}
+	chain = append(chain, peerIp)
+	index := len(chain) - 1 - hops
+	if index < 0 {
+		index = 0
+	}
+	return chain[index]
+}
+
+func peerIpFromContext(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestResolveClientIp(t *testing.T) {
+	tests := []struct {
+		name             string
+		peerAddr         string
+		forwardedFor     []string
+		trustedProxyHops int
+		want             string
+	}{
+		{name: "no proxy uses peer", peerAddr: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "no proxy ignores spoofed header", peerAddr: "203.0.113.7:5000", forwardedFor: []string{"1.2.3.4"}, want: "203.0.113.7"},
+		{name: "one proxy takes rightmost entry", peerAddr: "10.0.0.2:5000", forwardedFor: []string{"1.2.3.4, 198.51.100.9"}, trustedProxyHops: 1, want: "198.51.100.9"},
+		{name: "two proxies", peerAddr: "10.0.0.2:5000", forwardedFor: []string{"1.2.3.4, 198.51.100.9, 10.0.0.1"}, trustedProxyHops: 2, want: "198.51.100.9"},
+		{name: "multiple header values", peerAddr: "10.0.0.2:5000", forwardedFor: []string{"1.2.3.4", "198.51.100.9"}, trustedProxyHops: 1, want: "198.51.100.9"},
+		// loopback tidak otomatis dianggap gateway, hop gateway dihitung clientIpMiddleware
+		{name: "loopback peer is not a proxy", peerAddr: "127.0.0.1:5000", forwardedFor: []string{"1.2.3.4, 198.51.100.9"}, want: "127.0.0.1"},
+		{name: "short chain clamps to leftmost", peerAddr: "10.0.0.2:5000", forwardedFor: []string{"198.51.100.9"}, trustedProxyHops: 3, want: "198.51.100.9"},
+		{name: "missing header with proxy", peerAddr: "10.0.0.2:5000", trustedProxyHops: 1, want: "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.peerAddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			if len(tt.forwardedFor) > 0 {
+				md := metadata.MD{}
+				md.Append("x-forwarded-for", tt.forwardedFor...)
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+			if got := ResolveClientIp(ctx, tt.trustedProxyHops); got != tt.want {
+				t.Errorf("ResolveClientIp() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIpFromContextIgnoresForwardedFor(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 5000}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", "1.2.3.4"))
+	if got := ClientIpFromContext(ctx); got != "203.0.113.7" {
+		t.Errorf("ClientIpFromContext() = %q, want peer address", got)
+	}
+	if got := ClientIpFromContext(WithClientIp(ctx, "198.51.100.9")); got != "198.51.100.9" {
+		t.Errorf("ClientIpFromContext() = %q, want resolved ip", got)
+	}
+}
@@ -32,3 +32,7 @@ func ValidationErrorResponse(validationErrors []*common.ValidationError) *common
 func UnauthenticatedResponse() error {
 	return status.Error(codes.Unauthenticated, "unauthenticated")
 }
+
+func PermissionDeniedResponse() error {
+	return status.Error(codes.PermissionDenied, "permission denied")
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+)
+
+// anonymizeUserWorker menghapus data pribadi akun yang sudah dihapus
+// setelah masa tenggang habis (UU PDP).
+type anonymizeUserWorker struct {
+	authRepository repository.IAuthRepository
+	interval       time.Duration
+	gracePeriod    time.Duration
+}
+
+// Run berjalan sampai ctx selesai, dipanggil di goroutine sendiri
+func (w *anonymizeUserWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setiap putaran punya request id sendiri agar log dan query-nya bisa dikorelasikan
+func (w *anonymizeUserWorker) run(ctx context.Context) {
+	requestId := "job-" + utils.NewRequestId()
+	jobLogger := slog.Default().With("job", "anonymize_user", "request_id", requestId)
+	ctx = logger.WithContext(utils.WithRequestId(ctx, requestId), jobLogger)
+	ctx, span := tracing.Start(ctx, "AnonymizeUserWorker.run")
+	defer span.End()
+
+	count, err := w.authRepository.AnonymizeDeletedUsers(ctx, time.Now().Add(-w.gracePeriod))
+	if err != nil {
+		jobLogger.Error("anonymize user worker failed", "error", err)
+		return
+	}
+	if count > 0 {
+		jobLogger.Info("anonymize user worker: users anonymized", "count", count)
+	}
+}
+
+func NewAnonymizeUserWorker(authRepository repository.IAuthRepository, interval time.Duration, gracePeriod time.Duration) *anonymizeUserWorker {
+	return &anonymizeUserWorker{
+		authRepository: authRepository,
+		interval:       interval,
+		gracePeriod:    gracePeriod,
+	}
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+)
+
+// idempotencyKeyCleanupWorker menghapus idempotency key yang sudah kedaluwarsa
+type idempotencyKeyCleanupWorker struct {
+	idempotencyRepository repository.IIdempotencyRepository
+	interval              time.Duration
+}
+
+// Run berjalan sampai ctx selesai, dipanggil di goroutine sendiri
+func (w *idempotencyKeyCleanupWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *idempotencyKeyCleanupWorker) run(ctx context.Context) {
+	requestId := "job-" + utils.NewRequestId()
+	jobLogger := slog.Default().With("job", "idempotency_key_cleanup", "request_id", requestId)
+	ctx = logger.WithContext(utils.WithRequestId(ctx, requestId), jobLogger)
+	ctx, span := tracing.Start(ctx, "IdempotencyKeyCleanupWorker.run")
+	defer span.End()
+
+	count, err := w.idempotencyRepository.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		jobLogger.Error("idempotency key cleanup worker failed", "error", err)
+		return
+	}
+	if count > 0 {
+		jobLogger.Info("idempotency key cleanup worker: keys deleted", "count", count)
+	}
+}
+
+func NewIdempotencyKeyCleanupWorker(idempotencyRepository repository.IIdempotencyRepository, interval time.Duration) *idempotencyKeyCleanupWorker {
+	return &idempotencyKeyCleanupWorker{
+		idempotencyRepository: idempotencyRepository,
+		interval:              interval,
+	}
+}
@@ -2,58 +2,362 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"time"
 
-	"github.com/joho/godotenv"
 	gocache "github.com/patrickmn/go-cache"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/audit"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/config"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity"
+	jwtentity "github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/entity/jwt"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/gateway"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/grpcmiddleware"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/handler"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/healthcheck"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/oidclogin"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/passwordhash"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/passwordpolicy"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/repository"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/service"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/utils"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/internal/worker"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/migrations"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/address"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/admin"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pb/auth"
 	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/database"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/lifecycle"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/mailer"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/metrics"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/migration"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/ratelimit"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
-	ctx := context.Background()
-	godotenv.Load()
-	lis, err := net.Listen("tcp", ":50051")
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrate(os.Args[2:])
+	} else {
+		err = run()
+	}
+	if err != nil {
+		slog.Error("exiting", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	appLogger, err := logger.New(os.Stdout, cfg.Log.Format, cfg.Log.Level)
 	if err != nil {
-		log.Panicf("failed to listen: %v", err)
+		return err
 	}
+	// log.Print dari library pihak ketiga ikut tertulis lewat slog
+	slog.SetDefault(appLogger.With("environment", cfg.Environment))
 
-	db := database.ConnectDB(ctx, os.Getenv("DB_URI"))
+	app := lifecycle.NewManager(cfg.Server.ShutdownTimeout)
+	ctx := app.Context()
+	// resource yang sudah terbuka tetap ditutup jika startup gagal di tengah jalan
+	fail := func(err error) error {
+		return errors.Join(err, app.Shutdown())
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:     cfg.Tracing.Exporter,
+		OtlpEndpoint: cfg.Tracing.OtlpEndpoint,
+		OtlpInsecure: cfg.Tracing.OtlpInsecure,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+		Environment:  cfg.Environment,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to setup tracing: %w", err))
+	}
+	// didaftarkan pertama agar ditutup terakhir, setelah span dari request terakhir selesai
+	app.OnShutdown("tracing", shutdownTracing)
+
+	db, err := database.ConnectDB(ctx, database.Config{
+		Uri:                 cfg.Database.Uri,
+		MaxOpenConns:        cfg.Database.MaxOpenConns,
+		MaxIdleConns:        cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:     cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:     cfg.Database.ConnMaxIdleTime,
+		ConnectTimeout:      cfg.Database.ConnectTimeout,
+		ConnectRetryTimeout: cfg.Database.ConnectRetryTimeout,
+		StatementTimeout:    cfg.Database.StatementTimeout,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to connect database: %w", err))
+	}
+	app.OnShutdown("database", func(ctx context.Context) error {
+		return db.Close()
+	})
+	if cfg.Database.StatsInterval > 0 {
+		app.Go("database pool stats", database.ReportPoolStats(db, cfg.Database.StatsInterval))
+	}
+	if err := metrics.RegisterDB(db, "postgres"); err != nil {
+		return fail(fmt.Errorf("failed to register database metrics: %w", err))
+	}
+
+	migrator, err := migration.NewMigrator(db, migrations.FS)
+	if err != nil {
+		return fail(fmt.Errorf("failed to load migrations: %w", err))
+	}
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return fail(fmt.Errorf("failed to run migrations: %w", err))
+		}
+		slog.Info("database migrated", "version", migrator.LatestVersion(), "applied", applied)
+	}
 
 	cacheService := gocache.New(time.Hour*24, time.Hour)
+	app.OnShutdown("cache", func(ctx context.Context) error {
+		cacheService.Flush()
+		return nil
+	})
 
-	authMiddleware := grpcmiddleware.NewAuthMiddleware(cacheService)
+	keyManager, err := jwtentity.NewKeyManager(jwtentity.KeyManagerConfig{
+		Algorithm:        cfg.Jwt.SigningAlg,
+		KeysDir:          cfg.Jwt.KeysDir,
+		RotationInterval: cfg.Jwt.RotationInterval,
+		Overlap:          cfg.Jwt.Overlap,
+		Propagation:      cfg.Jwt.Propagation,
+		HmacSecret:       cfg.Jwt.SecretKey,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to load jwt keys: %w", err))
+	}
+	if cfg.Jwt.KeysDir == "" && cfg.Jwt.SigningAlg != jwtentity.AlgorithmHS256 {
+		// hanya bisa terjadi di dev, di luar dev JWT_KEYS_DIR wajib diisi
+		slog.Warn("JWT_KEYS_DIR is empty: signing keys only live in memory, every token becomes invalid when the server restarts and replicas cannot verify each other's tokens")
+	}
+	app.Go("jwt key rotation", keyManager.RunRotation)
 
+	txManager := database.NewTransactionManager(db)
 	authRepository := repository.NewAuthRepository(db)
+	adminUserRepository := repository.NewAdminUserRepository(db)
+	auditLogRepository := repository.NewAuditLogRepository(db)
+	apiKeyRepository := repository.NewApiKeyRepository(db)
+	addressRepository := repository.NewAddressRepository(db)
+	idempotencyRepository := repository.NewIdempotencyRepository(db)
+	authMiddleware := grpcmiddleware.NewAuthMiddleware(cacheService, keyManager, apiKeyRepository, authRepository)
+
+	var mailService mailer.IMailer
+	if cfg.Smtp.Host != "" {
+		mailService = mailer.NewSmtpMailer(cfg.Smtp.Host, cfg.Smtp.Port, cfg.Smtp.Username, cfg.Smtp.Password, cfg.Smtp.From)
+	} else {
+		mailService = mailer.NewLogMailer()
+	}
 
-	authService := service.NewAuthService(authRepository, cacheService)
+	passwordPolicy := &passwordpolicy.Policy{
+		MinLength:            cfg.Password.MinLength,
+		RequireUpper:         cfg.Password.RequireUpper,
+		RequireLower:         cfg.Password.RequireLower,
+		RequireDigit:         cfg.Password.RequireDigit,
+		RequireSymbol:        cfg.Password.RequireSymbol,
+		DisallowPersonalInfo: true,
+	}
+	if err := passwordPolicy.LoadCommonPasswords(cfg.Password.CommonList); err != nil {
+		return fail(fmt.Errorf("failed to load common password list: %w", err))
+	}
+
+	passwordHasher, err := passwordhash.NewPasswordHasher(passwordhash.Config{
+		Algorithm:         cfg.Password.HashAlgorithm,
+		BcryptCost:        cfg.Password.BcryptCost,
+		Argon2Memory:      cfg.Password.Argon2MemoryKib,
+		Argon2Iterations:  cfg.Password.Argon2Iterations,
+		Argon2Parallelism: cfg.Password.Argon2Parallelism,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to create password hasher: %w", err))
+	}
+
+	oidcProviders := make([]oidclogin.ProviderConfig, 0, len(cfg.Oidc.Providers))
+	for _, provider := range cfg.Oidc.Providers {
+		oidcProviders = append(oidcProviders, oidclogin.ProviderConfig{
+			Name:         provider.Name,
+			IssuerUrl:    provider.IssuerUrl,
+			ClientId:     provider.ClientId,
+			ClientSecret: provider.ClientSecret,
+			RedirectUrl:  provider.RedirectUrl,
+		})
+	}
+	oidcVerifier := oidclogin.NewVerifier(oidcProviders)
+
+	authService := service.NewAuthService(txManager, authRepository, addressRepository, cacheService, keyManager, mailService, passwordPolicy, passwordHasher, oidcVerifier)
 	authHandler := handler.NewAuthHandler(authService)
 
+	auditLogger := audit.NewAuditLogger(auditLogRepository)
+	auditMiddleware := grpcmiddleware.NewAuditMiddleware(auditLogger)
+
+	adminUserService := service.NewAdminUserService(txManager, adminUserRepository, authRepository, auditLogger, cacheService, mailService, keyManager)
+	adminUserHandler := handler.NewAdminUserHandler(adminUserService)
+
+	auditLogService := service.NewAuditLogService(auditLogRepository)
+	auditLogHandler := handler.NewAuditLogHandler(auditLogService)
+
+	apiKeyService := service.NewApiKeyService(txManager, apiKeyRepository, authRepository, auditLogger)
+	apiKeyHandler := handler.NewApiKeyHandler(apiKeyService)
+
+	addressService := service.NewAddressService(txManager, addressRepository)
+	addressHandler := handler.NewAddressHandler(addressService)
+
+	anonymizeUserWorker := worker.NewAnonymizeUserWorker(authRepository, cfg.Worker.AnonymizeUserInterval, entity.AccountDeletionGracePeriod)
+	app.Go("anonymize user worker", anonymizeUserWorker.Run)
+	idempotencyKeyCleanupWorker := worker.NewIdempotencyKeyCleanupWorker(idempotencyRepository, cfg.Worker.IdempotencyKeyCleanupInterval)
+	app.Go("idempotency key cleanup worker", idempotencyKeyCleanupWorker.Run)
+
+	// secret acak per proses, hanya dikenal gateway REST di proses ini
+	var gatewaySecret string
+	if cfg.Server.HttpPort > 0 {
+		gatewaySecret, err = utils.GenerateRandomToken(32)
+		if err != nil {
+			return fail(fmt.Errorf("failed to generate gateway secret: %w", err))
+		}
+	}
+	clientIpMiddleware := grpcmiddleware.NewClientIpMiddleware(cfg.Server.TrustedProxyHops, gatewaySecret)
+	interceptors := []grpc.UnaryServerInterceptor{
+		clientIpMiddleware.Middleware,
+		grpcmiddleware.RequestIdMiddleware,
+		grpcmiddleware.LoggingMiddleware,
+		grpcmiddleware.MetricsMiddleware,
+		grpcmiddleware.ErrorMiddleware,
+	}
+	if cfg.RateLimit.Enabled {
+		// sudah divalidasi saat config dimuat
+		perIpLimit, _ := ratelimit.ParseLimit(cfg.RateLimit.PerIp)
+		defaultLimit, _ := ratelimit.ParseLimit(cfg.RateLimit.Default)
+		methodLimits, _ := cfg.RateLimit.MethodLimits()
+		rateLimitStore := ratelimit.NewMemoryStore()
+		app.Go("rate limit cleanup", rateLimitStore.RunCleanup(time.Minute))
+		ipRateLimitMiddleware := grpcmiddleware.NewIpRateLimitMiddleware(rateLimitStore, perIpLimit)
+		rateLimitMiddleware := grpcmiddleware.NewRateLimitMiddleware(rateLimitStore, defaultLimit, methodLimits)
+		interceptors = append(interceptors, ipRateLimitMiddleware.Middleware, authMiddleware.Middleware, rateLimitMiddleware.Middleware)
+	} else {
+		interceptors = append(interceptors, authMiddleware.Middleware)
+	}
+	idempotencyMiddleware := grpcmiddleware.NewIdempotencyMiddleware(txManager, idempotencyRepository)
+	interceptors = append(interceptors, idempotencyMiddleware.Middleware, auditMiddleware.Middleware)
+
 	serv := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			grpcmiddleware.ErrorMiddleware,
-			authMiddleware.Middleware,
-		),
+		// span per RPC, trace context dibaca dari metadata traceparent
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(interceptors...),
 	)
 
 	auth.RegisterAuthServiceServer(serv, authHandler)
+	admin.RegisterAdminUserServiceServer(serv, adminUserHandler)
+	admin.RegisterAuditLogServiceServer(serv, auditLogHandler)
+	admin.RegisterApiKeyServiceServer(serv, apiKeyHandler)
+	address.RegisterAddressServiceServer(serv, addressHandler)
+	if err := idempotencyMiddleware.CheckMethods(serv.GetServiceInfo()); err != nil {
+		return fail(err)
+	}
+
+	healthServer := health.NewServer()
+	healthMonitor := healthcheck.NewMonitor(healthServer, cfg.Health.Interval, cfg.Health.Timeout)
+	healthMonitor.AddCheck(healthcheck.CheckPostgres, healthcheck.PostgresCheck(db))
+	healthMonitor.AddCheck(healthcheck.CheckCache, healthcheck.CacheCheck(cacheService))
+	requiredMigrationVersion := cfg.Health.RequiredMigrationVersion
+	if requiredMigrationVersion == 0 {
+		requiredMigrationVersion = migrator.LatestVersion()
+	}
+	healthMonitor.AddCheck(healthcheck.CheckMigration, healthcheck.MigrationCheck(db, requiredMigrationVersion))
+	serviceChecks := []string{healthcheck.CheckPostgres, healthcheck.CheckCache, healthcheck.CheckMigration}
+	for serviceName := range serv.GetServiceInfo() {
+		healthMonitor.AddService(serviceName, serviceChecks...)
+	}
+	healthpb.RegisterHealthServer(serv, healthServer)
+	app.Go("health monitor", healthMonitor.Run)
 
-	if os.Getenv("ENVIRONMENT") == "dev" {
+	if cfg.Server.Reflection {
 		reflection.Register(serv)
-		log.Println(" Reflection is registered") // hanya di development
+		slog.Info("grpc reflection is registered") // default hanya di dev dan stag
+	}
+
+	var httpServer *http.Server
+	var httpLis net.Listener
+	if cfg.Server.HttpPort > 0 {
+		gatewayHandler, err := gateway.NewHandler(ctx, fmt.Sprintf("localhost:%d", cfg.Server.Port), gatewaySecret)
+		if err != nil {
+			return fail(fmt.Errorf("failed to create gateway: %w", err))
+		}
+		if cfg.Server.GrpcWeb {
+			gatewayHandler = gateway.WithGrpcWeb(serv, cfg.Server.CorsAllowedOrigins, gatewayHandler)
+		}
+		httpServer = &http.Server{
+			Handler:           gateway.RequestIdHandler(gateway.TraceContextHandler(gatewayHandler)),
+			ReadHeaderTimeout: time.Second * 10,
+		}
+		httpLis, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.HttpPort))
+		if err != nil {
+			return fail(fmt.Errorf("failed to listen http: %w", err))
+		}
+	}
+
+	var metricsServer *http.Server
+	var metricsLis net.Listener
+	if cfg.Server.MetricsPort > 0 {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", metrics.Handler())
+		metricsServer = &http.Server{
+			Handler:           metricsMux,
+			ReadHeaderTimeout: time.Second * 10,
+		}
+		metricsLis, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.MetricsPort))
+		if err != nil {
+			if httpLis != nil {
+				httpLis.Close()
+			}
+			return fail(fmt.Errorf("failed to listen metrics: %w", err))
+		}
+	}
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
+	if err != nil {
+		if httpLis != nil {
+			httpLis.Close()
+		}
+		if metricsLis != nil {
+			metricsLis.Close()
+		}
+		return fail(fmt.Errorf("failed to listen: %w", err))
+	}
+
+	app.OnDrain(healthMonitor.Shutdown)
+	if metricsServer != nil {
+		// didaftarkan pertama agar berhenti paling akhir dan tetap bisa di-scrape selama drain
+		app.AddServer("metrics server", lifecycle.ServeHttp(metricsServer, metricsLis), lifecycle.StopHttpServer(metricsServer))
+		slog.Info("metrics server started", "port", cfg.Server.MetricsPort)
+	}
+	app.AddServer("grpc server", func() error {
+		return serv.Serve(lis)
+	}, lifecycle.StopGrpcServer(serv))
+	slog.Info("grpc server started", "port", cfg.Server.Port)
+	if httpServer != nil {
+		// didaftarkan setelah gRPC agar berhenti lebih dulu saat shutdown
+		app.AddServer("http gateway", lifecycle.ServeHttp(httpServer, httpLis), lifecycle.StopHttpServer(httpServer))
+		slog.Info("http gateway started", "port", cfg.Server.HttpPort, "grpc_web", cfg.Server.GrpcWeb)
 	}
 
-	log.Println("Server started on port 50051")
-	if err := serv.Serve(lis); err != nil {
-		log.Panicf("failed to serve: %v", err)
+	if err := app.Run(); err != nil {
+		return err
 	}
+	slog.Info("server stopped")
+	return nil
 }
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS user_role (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ,
+    created_by VARCHAR(255),
+    updated_by VARCHAR(255),
+    deleted_at TIMESTAMPTZ,
+    deleted_by VARCHAR(255),
+    is_deleted BOOLEAN NOT NULL DEFAULT false
+);
+
+INSERT INTO user_role (name, code, created_by) VALUES
+    ('Admin', 'admin', 'system'),
+    ('Customer', 'customer', 'system'),
+    ('Service Account', 'service_account', 'system')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS "user" (
+    id UUID PRIMARY KEY,
+    full_name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL DEFAULT '',
+    role_code VARCHAR(50) NOT NULL REFERENCES user_role (code),
+    phone_number VARCHAR(50),
+    avatar_url TEXT,
+    preferences JSONB NOT NULL DEFAULT '{}',
+    is_disabled BOOLEAN NOT NULL DEFAULT false,
+    password_reset_required BOOLEAN NOT NULL DEFAULT false,
+    anonymized_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ,
+    created_by VARCHAR(255),
+    updated_by VARCHAR(255),
+    deleted_at TIMESTAMPTZ,
+    deleted_by VARCHAR(255),
+    is_deleted BOOLEAN NOT NULL DEFAULT false
+);
+
+-- email boleh dipakai ulang setelah akun dihapus
+CREATE UNIQUE INDEX IF NOT EXISTS user_email_active_idx ON "user" (email) WHERE is_deleted IS false;
+CREATE INDEX IF NOT EXISTS user_role_code_idx ON "user" (role_code);
+CREATE INDEX IF NOT EXISTS user_created_at_idx ON "user" (created_at);
+-- dipakai worker anonimisasi
+CREATE INDEX IF NOT EXISTS user_pending_anonymize_idx ON "user" (deleted_at) WHERE is_deleted IS true AND anonymized_at IS NULL;
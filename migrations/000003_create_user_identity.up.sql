@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS user_identity (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES "user" (id),
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identity_user_id_idx ON user_identity (user_id);
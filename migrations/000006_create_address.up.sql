@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS address (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES "user" (id),
+    label VARCHAR(100) NOT NULL,
+    recipient_name VARCHAR(255) NOT NULL,
+    phone_number VARCHAR(50) NOT NULL,
+    street_address TEXT NOT NULL,
+    province VARCHAR(255) NOT NULL,
+    city VARCHAR(255) NOT NULL,
+    district VARCHAR(255) NOT NULL,
+    sub_district VARCHAR(255) NOT NULL,
+    postal_code VARCHAR(5) NOT NULL,
+    notes TEXT,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    is_default_shipping BOOLEAN NOT NULL DEFAULT false,
+    is_default_billing BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ,
+    created_by VARCHAR(255),
+    updated_by VARCHAR(255),
+    deleted_at TIMESTAMPTZ,
+    deleted_by VARCHAR(255),
+    is_deleted BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS address_user_id_idx ON address (user_id) WHERE is_deleted IS false;
+-- satu alamat default per jenis untuk setiap user
+CREATE UNIQUE INDEX IF NOT EXISTS address_default_shipping_idx ON address (user_id) WHERE is_default_shipping IS true AND is_deleted IS false;
+CREATE UNIQUE INDEX IF NOT EXISTS address_default_billing_idx ON address (user_id) WHERE is_default_billing IS true AND is_deleted IS false;
@@ -0,0 +1,3 @@
+-- token yang terbit sebelum waktu ini ditolak, disimpan di database agar
+-- berlaku untuk semua replica dan tidak hilang saat restart
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS tokens_valid_after TIMESTAMPTZ;
@@ -0,0 +1,12 @@
+-- perubahan email yang menunggu konfirmasi, disimpan di database agar token
+-- bisa dikonfirmasi di replica mana pun dan tidak hilang saat restart
+CREATE TABLE IF NOT EXISTS pending_email_change (
+    -- sha256 dari token yang dikirim ke email baru
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES "user" (id),
+    new_email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS pending_email_change_user_id_idx ON pending_email_change (user_id);
@@ -0,0 +1,11 @@
+-- token reset password, disimpan di database agar bisa ditukar di replica mana pun
+-- dan tidak hilang saat restart
+CREATE TABLE IF NOT EXISTS password_reset_token (
+    -- sha256 dari token yang dikirim ke email user
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES "user" (id),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_token_user_id_idx ON password_reset_token (user_id);
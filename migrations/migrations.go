@@ -0,0 +1,9 @@
+// Package migrations berisi skema database dalam bentuk file SQL berversi
+// (format golang-migrate: <versi>_<nama>.up.sql dan .down.sql).
+// Tambahkan tabel baru sebagai file dengan versi berikutnya, jangan ubah file yang sudah dirilis.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -3,20 +3,100 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+const (
+	initialConnectBackoff = time.Millisecond * 500
+	maxConnectBackoff     = time.Second * 10
+)
+
+type Config struct {
+	Uri             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// batas waktu satu kali ping saat startup
+	ConnectTimeout time.Duration
+	// total waktu mencoba koneksi saat startup sebelum menyerah
+	ConnectRetryTimeout time.Duration
+	// statement_timeout per koneksi, 0 berarti mengikuti setting server
+	StatementTimeout time.Duration
+}
 
-func ConnectDB(ctx context.Context,connStr string) *sql.DB {
-	db, err := sql.Open("postgres", connStr)
+// ConnectDB membuka pool koneksi postgres dan menunggu sampai database bisa
+// dihubungi, dengan retry dan backoff eksponensial selama ConnectRetryTimeout
+func ConnectDB(ctx context.Context, cfg Config) (*sql.DB, error) {
+	connector, err := pq.NewConnector(cfg.Uri)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	db := sql.OpenDB(&statementTimeoutConnector{
+		Connector:        connector,
+		statementTimeout: cfg.StatementTimeout,
+	})
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := ping(ctx, db, cfg); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func ping(ctx context.Context, db *sql.DB, cfg Config) error {
+	deadline := time.Now().Add(cfg.ConnectRetryTimeout)
+	backoff := initialConnectBackoff
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("database unreachable after %d attempt(s): %w", attempt, err)
+		}
+		slog.Warn("database: ping failed, retrying", "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// statementTimeoutConnector mengatur statement_timeout lewat SET setelah koneksi
+// dibuka, bukan lewat startup parameter yang sering ditolak session pooler
+type statementTimeoutConnector struct {
+	driver.Connector
+	statementTimeout time.Duration
+}
+
+func (c *statementTimeoutConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil || c.statementTimeout <= 0 {
+		return conn, err
+	}
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("database: driver connection does not support ExecContext")
 	}
-	db.PingContext(ctx)
+	_, err = execer.ExecContext(ctx, fmt.Sprintf("SET statement_timeout = %d", c.statementTimeout.Milliseconds()), nil)
 	if err != nil {
-		panic(err)
+		conn.Close()
+		return nil, err
 	}
-	return db
-	
-}
\ No newline at end of file
+	return conn, nil
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// PoolStats adalah ringkasan sql.DBStats untuk dilaporkan ke log atau metrics
+type PoolStats struct {
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration"`
+	MaxIdleClosed      int64         `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64         `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64         `json:"max_lifetime_closed"`
+}
+
+func Stats(db *sql.DB) PoolStats {
+	stats := db.Stats()
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// ReportPoolStats menulis statistik pool ke log setiap interval, ditandai
+// ketika ada request yang harus menunggu koneksi (pool penuh)
+func ReportPoolStats(db *sql.DB, interval time.Duration) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var lastWaitCount int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stats := Stats(db)
+				saturated := stats.WaitCount > lastWaitCount
+				lastWaitCount = stats.WaitCount
+				level := slog.LevelInfo
+				if saturated {
+					level = slog.LevelWarn
+				}
+				slog.Log(ctx, level, "database pool stats",
+					"open", stats.OpenConnections,
+					"in_use", stats.InUse,
+					"idle", stats.Idle,
+					"max_open", stats.MaxOpenConnections,
+					"wait_count", stats.WaitCount,
+					"wait_duration", stats.WaitDuration,
+					"saturated", saturated,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultMaxAttempts  = 3
+	defaultRetryBackoff = time.Millisecond * 20
+)
+
+// DBTX dipenuhi oleh *sql.DB dan *sql.Tx
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+type txState struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// Executor mengembalikan transaksi yang sedang berjalan di ctx, atau db jika
+// tidak ada. Repository memakai ini agar otomatis ikut transaksi pemanggil.
+func Executor(ctx context.Context, db *sql.DB) DBTX {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return tracedDB{db: state.tx}
+	}
+	return tracedDB{db: db}
+}
+
+type TxOption func(options *sql.TxOptions)
+
+// WithIsolation mengatur isolation level, hanya berlaku untuk transaksi terluar
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(options *sql.TxOptions) {
+		options.Isolation = level
+	}
+}
+
+type ITransactionManager interface {
+	// WithinTransaction menjalankan fn dalam satu transaksi yang dibawa ctx.
+	// Pemanggilan bersarang memakai savepoint, sehingga error di dalam hanya
+	// membatalkan bagian tersebut. Transaksi terluar diulang saat terjadi
+	// serialization failure atau deadlock, jadi fn harus aman dijalankan ulang
+	// dan efek di luar database (cache, email) dilakukan setelah commit.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+}
+
+type transactionManager struct {
+	db           *sql.DB
+	maxAttempts  int
+	retryBackoff time.Duration
+}
+
+func (m *transactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return withinSavepoint(ctx, state, fn)
+	}
+
+	options := &sql.TxOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var err error
+	for attempt := 1; attempt <= m.maxAttempts; attempt++ {
+		err = m.run(ctx, options, fn, attempt)
+		if err == nil || !isRetryable(err) || attempt == m.maxAttempts {
+			return err
+		}
+		// backoff eksponensial dengan jitter agar transaksi yang bentrok tidak bertabrakan lagi
+		backoff := m.retryBackoff << (attempt - 1)
+		backoff += time.Duration(rand.Int64N(int64(backoff)))
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+	return err
+}
+
+func (m *transactionManager) run(ctx context.Context, options *sql.TxOptions, fn func(ctx context.Context) error, attempt int) (err error) {
+	ctx, span := tracing.Start(ctx, "db transaction", attribute.Int("db.transaction.attempt", attempt))
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := m.db.BeginTx(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tx.Rollback()
+			panic(recovered)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, &txState{tx: tx}))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func withinSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) (err error) {
+	state.savepoints++
+	savepoint := fmt.Sprintf("sp_%d", state.savepoints)
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			state.tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+savepoint)
+			panic(recovered)
+		}
+		if err != nil {
+			if _, rollbackErr := state.tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+savepoint); rollbackErr != nil {
+				err = errors.Join(err, rollbackErr)
+			}
+		}
+	}()
+
+	if err = fn(ctx); err != nil {
+		return err
+	}
+	_, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+	return err
+}
+
+// serialization_failure dan deadlock_detected aman diulang dari awal transaksi
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
+func NewTransactionManager(db *sql.DB) ITransactionManager {
+	return &transactionManager{
+		db:           db,
+		maxAttempts:  defaultMaxAttempts,
+		retryBackoff: defaultRetryBackoff,
+	}
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// recordingConnector adalah driver database/sql palsu yang hanya mencatat statement
+type recordingConnector struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *recordingConnector) record(statement string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.log = append(c.log, statement)
+}
+
+func (c *recordingConnector) statements() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return strings.Join(c.log, "; ")
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if level := sql.IsolationLevel(opts.Isolation); level != sql.LevelDefault {
+		c.connector.record("BEGIN " + level.String())
+	} else {
+		c.connector.record("BEGIN")
+	}
+	return c, nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.connector.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) Commit() error {
+	c.connector.record("COMMIT")
+	return nil
+}
+
+func (c *recordingConn) Rollback() error {
+	c.connector.record("ROLLBACK")
+	return nil
+}
+
+func newTestTransactionManager(t *testing.T) (*transactionManager, *recordingConnector) {
+	t.Helper()
+	connector := &recordingConnector{}
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() { db.Close() })
+	return &transactionManager{db: db, maxAttempts: defaultMaxAttempts, retryBackoff: time.Microsecond}, connector
+}
+
+func exec(ctx context.Context, db *sql.DB, query string) error {
+	_, err := Executor(ctx, db).ExecContext(ctx, query)
+	return err
+}
+
+func TestWithinTransaction(t *testing.T) {
+	errFailed := errors.New("failed")
+	serializationFailure := &pq.Error{Code: "40001"}
+
+	tests := []struct {
+		name         string
+		opts         []TxOption
+		fn           func(ctx context.Context, tm *transactionManager, attempt int) error
+		wantErr      error
+		wantAttempts int
+		wantLog      string
+	}{
+		{
+			name: "commit",
+			fn: func(ctx context.Context, tm *transactionManager, attempt int) error {
+				return exec(ctx, tm.db, "INSERT 1")
+			},
+			wantAttempts: 1,
+			wantLog:      "BEGIN; INSERT 1; COMMIT",
+		},
+		{
+			name: "isolation level",
+			opts: []TxOption{WithIsolation(sql.LevelSerializable)},
+			fn: func(ctx context.Context, tm *transactionManager, attempt int) error {
+				return nil
+			},
+			wantAttempts: 1,
+			wantLog:      "BEGIN Serializable; COMMIT",
+		},
+		{
+			name: "rollback on error",
+			fn: func(ctx context.Context, tm *transactionManager, attempt int) error {
+				if err := exec(ctx, tm.db, "INSERT 1"); err != nil {
+					return err
+				}
+				return errFailed
+			},
+			wantErr:      errFailed,
+			wantAttempts: 1,
+			wantLog:      "BEGIN; INSERT 1; ROLLBACK",
+		},
+		{
+			name: "nested uses savepoint",
+			fn: func(ctx context.Context, tm *transactionManager, attempt int) error {
+				return tm.WithinTransaction(ctx, func(ctx context.Context) error {
+					return exec(ctx, tm.db, "INSERT 2")
+				}, WithIsolation(sql.LevelSerializable))
+			},
+			wantAttempts: 1,
+			wantLog:      "BEGIN; SAVEPOINT sp_1; INSERT 2; RELEASE SAVEPOINT sp_1; COMMIT",
+		},
+		{
+			// error di dalam hanya membatalkan savepoint, transaksi luar tetap commit
+			name: "nested error rolls back to savepoint",
+			fn: func(ctx context.Context, tm *transactionManager, attempt int) error {
+				err := tm.WithinTransaction(ctx, func(ctx context.Context) error {
+					if err := exec(ctx, tm.db, "INSERT 2"); err != nil {
+						return err
+					}
+					return errFailed
+				})
+				if !errors.Is(err, errFailed) {
+					return fmt.Errorf("nested error = %v", err)
+				}
+				return exec(ctx, tm.db, "INSERT 3")
+			},
+			wantAttempts: 1,
+			wantLog:      "BEGIN; SAVEPOINT sp_1; INSERT 2; ROLLBACK TO SAVEPOINT sp_1; INSERT 3; COMMIT",
+		},
+		{
+			name: "retry on serialization failure",
+			fn: func(ctx context.Context, tm *transactionManager, attempt int) error {
+				if attempt == 1 {
+					return serializationFailure
+				}
+				return nil
+			},
+			wantAttempts: 2,
+			wantLog:      "BEGIN; ROLLBACK; BEGIN; COMMIT",
+		},
+		{
+			name: "retry exhausted",
+			fn: func(ctx context.Context, tm *transactionManager, attempt int) error {
+				return &pq.Error{Code: "40P01"}
+			},
+			wantErr:      &pq.Error{Code: "40P01"},
+			wantAttempts: defaultMaxAttempts,
+			wantLog:      "BEGIN; ROLLBACK; BEGIN; ROLLBACK; BEGIN; ROLLBACK",
+		},
+		{
+			name: "unique violation is not retried",
+			fn: func(ctx context.Context, tm *transactionManager, attempt int) error {
+				return &pq.Error{Code: "23505"}
+			},
+			wantErr:      &pq.Error{Code: "23505"},
+			wantAttempts: 1,
+			wantLog:      "BEGIN; ROLLBACK",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm, connector := newTestTransactionManager(t)
+			attempts := 0
+			err := tm.WithinTransaction(context.Background(), func(ctx context.Context) error {
+				attempts++
+				return tt.fn(ctx, tm, attempts)
+			}, tt.opts...)
+
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("WithinTransaction() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Errorf("WithinTransaction() error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if got := connector.statements(); got != tt.wantLog {
+				t.Errorf("statements = %q, want %q", got, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestWithinTransactionPanic(t *testing.T) {
+	tm, connector := newTestTransactionManager(t)
+	defer func() {
+		if recovered := recover(); recovered != "boom" {
+			t.Errorf("recover() = %v, want boom", recovered)
+		}
+		if got := connector.statements(); got != "BEGIN; ROLLBACK" {
+			t.Errorf("statements = %q, want rollback after panic", got)
+		}
+	}()
+	tm.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		panic("boom")
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pq.Error{Code: "40P01"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("update user: %w", &pq.Error{Code: "40001"}), want: true},
+		{name: "unique violation", err: &pq.Error{Code: "23505"}, want: false},
+		{name: "not a postgres error", err: errors.New("failed"), want: false},
+		{name: "no rows", err: sql.ErrNoRows, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
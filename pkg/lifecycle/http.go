@@ -0,0 +1,31 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ServeHttp menjalankan server HTTP pada listener, http.ErrServerClosed
+// setelah Shutdown tidak dianggap error
+func ServeHttp(server *http.Server, lis net.Listener) func() error {
+	return func() error {
+		if err := server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// StopHttpServer menunggu request yang sedang berjalan selesai dan
+// menutup paksa koneksi jika ctx habis lebih dulu
+func StopHttpServer(server *http.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := server.Shutdown(ctx); err != nil {
+			server.Close()
+			return err
+		}
+		return nil
+	}
+}
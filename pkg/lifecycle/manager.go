@@ -0,0 +1,186 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type closer struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+type server struct {
+	name  string
+	serve func() error
+	stop  func(ctx context.Context) error
+}
+
+// Manager mengatur urutan shutdown aplikasi:
+// hook drain dipanggil, server berhenti menerima request, worker dihentikan
+// dan ditunggu, lalu resource (DB, cache) ditutup dengan urutan terbalik dari pendaftaran.
+// Tiap tahap (server, worker, resource) punya batas waktu shutdownTimeout sendiri.
+type Manager struct {
+	shutdownTimeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	workers sync.WaitGroup
+
+	mu      sync.Mutex
+	drains  []func()
+	servers []server
+	closers []closer
+}
+
+func NewManager(shutdownTimeout time.Duration) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		shutdownTimeout: shutdownTimeout,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Context selesai ketika shutdown dimulai, dipakai oleh worker
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Go menjalankan worker di goroutine terpisah. fn harus berhenti ketika ctx selesai.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		fn(m.ctx)
+		slog.Info("lifecycle: worker stopped", "name", name)
+	}()
+}
+
+// OnShutdown mendaftarkan resource yang ditutup saat shutdown, seperti defer:
+// yang terakhir didaftarkan ditutup lebih dulu
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, closer{name: name, fn: fn})
+}
+
+// OnDrain dipanggil paling awal saat shutdown, sebelum server berhenti,
+// misalnya untuk mengubah status health menjadi NOT_SERVING
+func (m *Manager) OnDrain(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drains = append(m.drains, fn)
+}
+
+// AddServer mendaftarkan server yang dijalankan oleh Run. Server dihentikan
+// dengan urutan terbalik, jadi daftarkan server yang menjadi dependensi lebih dulu.
+func (m *Manager) AddServer(name string, serve func() error, stop func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers = append(m.servers, server{name: name, serve: serve, stop: stop})
+}
+
+// Run menjalankan semua server sampai SIGINT/SIGTERM diterima atau salah satu
+// server gagal, kemudian menghentikan server dan memanggil Shutdown. Error
+// dikembalikan agar main bisa keluar dengan exit code non-zero.
+func (m *Manager) Run() error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	m.mu.Lock()
+	servers := m.servers
+	m.mu.Unlock()
+
+	serveErr := make(chan error, len(servers))
+	for _, s := range servers {
+		go func(s server) {
+			if err := s.serve(); err != nil {
+				serveErr <- fmt.Errorf("serve %s: %w", s.name, err)
+				return
+			}
+			serveErr <- nil
+		}(s)
+	}
+
+	var runErr error
+	select {
+	case sig := <-signals:
+		slog.Info("lifecycle: shutting down", "signal", sig.String())
+	case runErr = <-serveErr:
+	}
+
+	m.mu.Lock()
+	drains := m.drains
+	m.mu.Unlock()
+	for _, drain := range drains {
+		drain()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+	for i := len(servers) - 1; i >= 0; i-- {
+		if err := servers[i].stop(ctx); err != nil {
+			runErr = errors.Join(runErr, fmt.Errorf("stop %s: %w", servers[i].name, err))
+			continue
+		}
+		slog.Info("lifecycle: server stopped", "name", servers[i].name)
+	}
+	return errors.Join(runErr, m.Shutdown())
+}
+
+// Shutdown menghentikan worker dan menutup resource tanpa menjalankan server,
+// misalnya ketika startup gagal di tengah jalan
+func (m *Manager) Shutdown() error {
+	m.cancel()
+	if err := m.waitWorkers(); err != nil {
+		// worker yang belum berhenti mungkin masih memakai DB, resource dibiarkan
+		// terbuka dan dilepas oleh OS saat proses keluar
+		return err
+	}
+	return m.close()
+}
+
+func (m *Manager) waitWorkers() error {
+	timer := time.NewTimer(m.shutdownTimeout)
+	defer timer.Stop()
+	drained := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-timer.C:
+		return errors.New("lifecycle: timed out waiting for workers, resources are left open")
+	}
+}
+
+func (m *Manager) close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+
+	m.mu.Lock()
+	closers := m.closers
+	m.closers = nil
+	m.mu.Unlock()
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("lifecycle: close %s: %w", closers[i].name, err))
+			continue
+		}
+		slog.Info("lifecycle: resource closed", "name", closers[i].name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package lifecycle
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestManagerShutdown(t *testing.T) {
+	const timeout = 200 * time.Millisecond
+	tests := []struct {
+		name string
+		// lama worker berhenti setelah ctx selesai, -1 berarti tidak pernah berhenti
+		workerDelay time.Duration
+		wantErr     bool
+		wantClosed  []string
+	}{
+		{name: "closes in reverse order", workerDelay: 0, wantClosed: []string{"cache", "db"}},
+		// worker memakai sebagian besar batas waktunya, resource tetap mendapat waktu sendiri
+		{name: "slow worker does not use up close deadline", workerDelay: timeout * 3 / 4, wantClosed: []string{"cache", "db"}},
+		{name: "stuck worker leaves resources open", workerDelay: -1, wantErr: true, wantClosed: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(timeout)
+			stuck := make(chan struct{})
+			defer close(stuck)
+			m.Go("worker", func(ctx context.Context) {
+				<-ctx.Done()
+				if tt.workerDelay < 0 {
+					<-stuck
+					return
+				}
+				time.Sleep(tt.workerDelay)
+			})
+
+			var closed []string
+			for _, name := range []string{"db", "cache"} {
+				m.OnShutdown(name, func(ctx context.Context) error {
+					// resource butuh sedikit waktu untuk ditutup
+					select {
+					case <-time.After(timeout / 4):
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+					closed = append(closed, name)
+					return nil
+				})
+			}
+
+			err := m.Shutdown()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Shutdown() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(closed, tt.wantClosed) {
+				t.Errorf("closed = %v, want %v", closed, tt.wantClosed)
+			}
+		})
+	}
+}
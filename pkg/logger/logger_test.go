@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		level   string
+		want    string
+		wantErr bool
+	}{
+		{name: "json", format: FormatJson, level: "info", want: `"msg":"hello"`},
+		{name: "text", format: FormatText, level: "debug", want: "msg=hello"},
+		{name: "format case insensitive", format: "JSON", level: "info", want: `"msg":"hello"`},
+		{name: "filtered by level", format: FormatJson, level: "warn", want: ""},
+		{name: "invalid level", format: FormatJson, level: "verbose", wantErr: true},
+		{name: "invalid format", format: "xml", level: "info", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, tt.format, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			logger.Info("hello")
+			if tt.want == "" && buf.Len() != 0 {
+				t.Errorf("output = %q, want empty", buf.String())
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output = %q, want containing %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != slog.Default() {
+		t.Error("FromContext() without logger does not return slog.Default()")
+	}
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)).With("request_id", "abc")
+	if got := FromContext(WithContext(context.Background(), logger)); got != logger {
+		t.Error("FromContext() does not return the logger stored by WithContext()")
+	}
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const redacted = "[REDACTED]"
+
+// field yang namanya mengandung kata ini tidak pernah ditulis ke log
+var sensitiveKeywords = []string{"password", "token", "secret"}
+
+var sensitiveFields = map[string]bool{
+	"api_key":       true,
+	"authorization": true,
+	// authorization code dan PKCE verifier OIDC
+	"code":          true,
+	"code_verifier": true,
+}
+
+// Redact mengubah request menjadi map untuk log dengan field sensitif
+// (password, token, secret) disamarkan
+func Redact(message proto.Message) any {
+	raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+	if err != nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil
+	}
+	return redactValue(value)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if IsSensitive(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func IsSensitive(field string) bool {
+	field = strings.ToLower(field)
+	if sensitiveFields[field] {
+		return true
+	}
+	for _, keyword := range sensitiveKeywords {
+		if strings.Contains(field, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestIsSensitive(t *testing.T) {
+	tests := []struct {
+		field string
+		want  bool
+	}{
+		{field: "password", want: true},
+		{field: "new_password", want: true},
+		{field: "refresh_token", want: true},
+		{field: "client_secret", want: true},
+		{field: "Authorization", want: true},
+		{field: "api_key", want: true},
+		{field: "code", want: true},
+		{field: "code_verifier", want: true},
+		{field: "email", want: false},
+		{field: "full_name", want: false},
+		// hanya nama persis, bukan substring
+		{field: "postal_code", want: false},
+		{field: "api_key_id", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := IsSensitive(tt.field); got != tt.want {
+				t.Errorf("IsSensitive(%q) = %v, want %v", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]any
+		want  map[string]any
+	}{
+		{
+			name:  "top level",
+			input: map[string]any{"email": "budi@example.com", "password": "Kursi#Jati9"},
+			want:  map[string]any{"email": "budi@example.com", "password": redacted},
+		},
+		{
+			name: "nested object",
+			input: map[string]any{"user": map[string]any{
+				"full_name":    "Budi",
+				"access_token": "eyJhbGciOi",
+			}},
+			want: map[string]any{"user": map[string]any{
+				"full_name":    "Budi",
+				"access_token": redacted,
+			}},
+		},
+		{
+			name: "inside list",
+			input: map[string]any{"providers": []any{
+				map[string]any{"name": "google", "client_secret": "s3cret"},
+			}},
+			want: map[string]any{"providers": []any{
+				map[string]any{"name": "google", "client_secret": redacted},
+			}},
+		},
+		{
+			// seluruh isi field sensitif disamarkan, termasuk object
+			name:  "sensitive object",
+			input: map[string]any{"token": map[string]any{"value": "abc"}},
+			want:  map[string]any{"token": redacted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := structpb.NewStruct(tt.input)
+			if err != nil {
+				t.Fatalf("NewStruct() error = %v", err)
+			}
+			if got := Redact(message); !reflect.DeepEqual(got, any(tt.want)) {
+				t.Errorf("Redact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
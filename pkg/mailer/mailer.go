@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/pkg/logger"
+)
+
+type IMailer interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func (m *smtpMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	msg := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n")
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg))
+}
+
+// logMailer hanya menulis email ke log, dipakai di development
+type logMailer struct{}
+
+func (m *logMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	logger.FromContext(ctx).Info("mail (log mailer)", "to", to, "subject", subject, "body", body)
+	return nil
+}
+
+func NewSmtpMailer(host string, port string, username string, password string, from string) IMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpMailer{
+		addr: fmt.Sprintf("%s:%s", host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+func NewLogMailer() IMailer {
+	return &logMailer{}
+}
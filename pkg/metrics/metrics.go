@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	LoginMethodPassword = "password"
+	LoginMethodOidc     = "oidc"
+)
+
+// Registry terpisah dari prometheus.DefaultRegisterer agar hanya metric
+// aplikasi ini (ditambah runtime Go dan proses) yang diekspos
+var Registry = prometheus.NewRegistry()
+
+var (
+	rpcHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Total RPC yang selesai, per method, kode gRPC dan status_code di BaseResponse.",
+	}, []string{"grpc_method", "grpc_code", "status_code"})
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Latency RPC per method.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"grpc_method"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_rate_limited_total",
+		Help: "Total request yang ditolak rate limiter, per method.",
+	}, []string{"grpc_method"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_lookups_total",
+		Help: "Total lookup cache in-memory, per jenis key dan hasil (hit/miss).",
+	}, []string{"cache", "result"})
+
+	registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_registrations_total",
+		Help: "Total user baru, per metode registrasi.",
+	}, []string{"method"})
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Total login berhasil, per metode login.",
+	}, []string{"method"})
+	loginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_failures_total",
+		Help: "Total login gagal, per metode login dan alasan.",
+	}, []string{"method", "reason"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcHandled,
+		rpcDuration,
+		rateLimited,
+		cacheLookups,
+		registrations,
+		logins,
+		loginFailures,
+	)
+}
+
+// Handler menyajikan metric dalam format Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB menambahkan gauge dan counter pool koneksi (sql.DBStats)
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+func ObserveRpc(method string, code string, statusCode string, seconds float64) {
+	rpcHandled.WithLabelValues(method, code, statusCode).Inc()
+	rpcDuration.WithLabelValues(method).Observe(seconds)
+}
+
+func RateLimited(method string) {
+	rateLimited.WithLabelValues(method).Inc()
+}
+
+func ObserveCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+func UserRegistered(method string) {
+	registrations.WithLabelValues(method).Inc()
+}
+
+func LoginSucceeded(method string) {
+	logins.WithLabelValues(method).Inc()
+}
+
+// LoginFailed mencatat login gagal. reason harus dari himpunan kecil yang tetap
+// (mis. invalid_password), jangan memakai email atau pesan error
+func LoginFailed(method string, reason string) {
+	loginFailures.WithLabelValues(method, reason).Inc()
+}
@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/xprasetio/be-ecommerce-furniture-grpc.git/migrations"
+)
+
+func file(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func TestNewMigrator(t *testing.T) {
+	tests := []struct {
+		name         string
+		source       fstest.MapFS
+		wantErr      bool
+		wantVersions []uint
+	}{
+		{
+			name: "sorted by version",
+			source: fstest.MapFS{
+				"000010_create_address.up.sql":   file("CREATE TABLE address ()"),
+				"000010_create_address.down.sql": file("DROP TABLE address"),
+				"000002_create_user.up.sql":      file("CREATE TABLE users ()"),
+				"000002_create_user.down.sql":    file("DROP TABLE users"),
+			},
+			wantVersions: []uint{2, 10},
+		},
+		{
+			name: "down file is optional",
+			source: fstest.MapFS{
+				"000001_create_user.up.sql": file("CREATE TABLE users ()"),
+			},
+			wantVersions: []uint{1},
+		},
+		{
+			name: "other files ignored",
+			source: fstest.MapFS{
+				"000001_create_user.up.sql": file("CREATE TABLE users ()"),
+				"README.md":                 file("catatan"),
+				"seed.sql":                  file("INSERT INTO users"),
+				"archive/000002_old.up.sql": file("CREATE TABLE old ()"),
+			},
+			wantVersions: []uint{1},
+		},
+		{
+			name:         "empty",
+			source:       fstest.MapFS{},
+			wantVersions: []uint{},
+		},
+		{
+			name: "version zero",
+			source: fstest.MapFS{
+				"000000_init.up.sql": file("SELECT 1"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "version used twice",
+			source: fstest.MapFS{
+				"000001_create_user.up.sql":    file("CREATE TABLE users ()"),
+				"000001_create_address.up.sql": file("CREATE TABLE address ()"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "down without up",
+			source: fstest.MapFS{
+				"000001_create_user.down.sql": file("DROP TABLE users"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrator, err := NewMigrator(nil, tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewMigrator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(migrator.migrations) != len(tt.wantVersions) {
+				t.Fatalf("got %d migrations, want %d", len(migrator.migrations), len(tt.wantVersions))
+			}
+			for i, version := range tt.wantVersions {
+				if migrator.migrations[i].Version != version {
+					t.Errorf("migrations[%d].Version = %d, want %d", i, migrator.migrations[i].Version, version)
+				}
+			}
+			var wantLatest uint
+			if len(tt.wantVersions) > 0 {
+				wantLatest = tt.wantVersions[len(tt.wantVersions)-1]
+			}
+			if got := migrator.LatestVersion(); got != wantLatest {
+				t.Errorf("LatestVersion() = %d, want %d", got, wantLatest)
+			}
+		})
+	}
+}
+
+func TestNewMigratorReadsContent(t *testing.T) {
+	migrator, err := NewMigrator(nil, fstest.MapFS{
+		"000001_create_user.up.sql":   file("CREATE TABLE users ()"),
+		"000001_create_user.down.sql": file("DROP TABLE users"),
+	})
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	got := migrator.migrations[0]
+	if got.Name != "create_user" || got.Up != "CREATE TABLE users ()" || got.Down != "DROP TABLE users" {
+		t.Errorf("migration = %+v", got)
+	}
+}
+
+// file migration yang dibawa binary harus berurutan dan bisa di-rollback
+func TestEmbeddedMigrations(t *testing.T) {
+	migrator, err := NewMigrator(nil, migrations.FS)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if len(migrator.migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, migration := range migrator.migrations {
+		if migration.Version != uint(i+1) {
+			t.Errorf("migration %s has version %d, want %d", migration.Name, migration.Version, i+1)
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+	}
+}
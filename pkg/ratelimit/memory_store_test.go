@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestStore() (*memoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+	tests := []struct {
+		name string
+		// jeda sebelum tiap Take
+		steps         []time.Duration
+		wantAllowed   []bool
+		wantRemaining []int
+	}{
+		{
+			name:          "burst up to capacity",
+			steps:         []time.Duration{0, 0, 0, 0},
+			wantAllowed:   []bool{true, true, true, false},
+			wantRemaining: []int{2, 1, 0, 0},
+		},
+		{
+			name:          "refills one token per period share",
+			steps:         []time.Duration{0, 0, 0, time.Second, 0},
+			wantAllowed:   []bool{true, true, true, true, false},
+			wantRemaining: []int{2, 1, 0, 0, 0},
+		},
+		{
+			name:          "never exceeds capacity",
+			steps:         []time.Duration{0, time.Hour, 0, 0, 0},
+			wantAllowed:   []bool{true, true, true, true, false},
+			wantRemaining: []int{2, 2, 1, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestStore()
+			for i, step := range tt.steps {
+				clock.now = clock.now.Add(step)
+				result, err := store.Take(context.Background(), "key", limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Allowed != tt.wantAllowed[i] || result.Remaining != tt.wantRemaining[i] {
+					t.Errorf("take %d = %+v, want allowed %v remaining %d", i, result, tt.wantAllowed[i], tt.wantRemaining[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRetryAfter(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Requests: 2, Period: 10 * time.Second}
+	store.Take(context.Background(), "key", limit)
+	store.Take(context.Background(), "key", limit)
+
+	clock.now = clock.now.Add(2 * time.Second)
+	result, _ := store.Take(context.Background(), "key", limit)
+	if result.Allowed {
+		t.Fatal("expected request to be rejected")
+	}
+	if diff := result.RetryAfter - 3*time.Second; diff < -time.Millisecond || diff > time.Millisecond {
+		t.Errorf("RetryAfter = %v, want 3s", result.RetryAfter)
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Requests: 1, Period: time.Minute}
+	if result, _ := store.Take(context.Background(), "a", limit); !result.Allowed {
+		t.Fatal("first request for a rejected")
+	}
+	if result, _ := store.Take(context.Background(), "b", limit); !result.Allowed {
+		t.Error("bucket b should not share tokens with a")
+	}
+	if result, _ := store.Take(context.Background(), "a", limit); result.Allowed {
+		t.Error("second request for a should be rejected")
+	}
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Requests: 2, Period: 10 * time.Second}
+	store.Take(context.Background(), "key", limit)
+
+	clock.now = clock.now.Add(time.Second)
+	store.cleanup()
+	if _, ok := store.buckets["key"]; !ok {
+		t.Fatal("bucket removed before it was full again")
+	}
+
+	clock.now = clock.now.Add(4 * time.Second)
+	store.cleanup()
+	if _, ok := store.buckets["key"]; ok {
+		t.Error("full bucket was not removed")
+	}
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit adalah token bucket berkapasitas Requests yang terisi penuh kembali
+// dalam satu Period, mis. 5/m: lima request beruntun lalu satu tiap 12 detik
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+type Result struct {
+	Allowed bool
+	// sisa token setelah request ini
+	Remaining int
+	// waktu tunggu sampai satu token tersedia, hanya diisi jika ditolak
+	RetryAfter time.Duration
+}
+
+// IStore menyimpan bucket per key. Implementasi in-memory hanya berlaku per
+// instance, pakai store bersama (mis. Redis) jika dijalankan lebih dari satu replica.
+type IStore interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+var periods = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseLimit membaca format <requests>/<s|m|h>, mis. 10/m
+func ParseLimit(raw string) (Limit, error) {
+	requestsStr, periodStr, ok := strings.Cut(strings.TrimSpace(raw), "/")
+	period, validPeriod := periods[periodStr]
+	requests, err := strconv.Atoi(requestsStr)
+	if !ok || !validPeriod || err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid limit %q, expected format like 10/m", raw)
+	}
+	return Limit{Requests: requests, Period: period}, nil
+}
@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Limit
+		wantErr bool
+	}{
+		{raw: "10/m", want: Limit{Requests: 10, Period: time.Minute}},
+		{raw: " 5/s ", want: Limit{Requests: 5, Period: time.Second}},
+		{raw: "100/h", want: Limit{Requests: 100, Period: time.Hour}},
+		{raw: "10", wantErr: true},
+		{raw: "10/d", wantErr: true},
+		{raw: "0/m", wantErr: true},
+		{raw: "-1/m", wantErr: true},
+		{raw: "abc/m", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseLimit(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}